@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxColumnNameLength = 20
+
+// NormalizeColumnName trims a board column name and checks it fits in a
+// column header.
+func NormalizeColumnName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errors.New("column name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > maxColumnNameLength {
+		return "", fmt.Errorf("column name must be at most %d characters", maxColumnNameLength)
+	}
+	return name, nil
+}
+
+// ParseWipLimit reads a column's WIP limit. An empty limit or 0 means none.
+func ParseWipLimit(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid WIP limit %q (want a number, 0 for none)", s)
+	}
+	return limit, nil
+}
+
+// CreateColumn adds a column at the right end of the board, rejecting names
+// that are already taken.
+func (d *Database) CreateColumn(ctx context.Context, name string) (orm.BoardColumn, error) {
+	if _, err := d.Queries.GetColumnByName(ctx, name); err == nil {
+		return orm.BoardColumn{}, fmt.Errorf("column %q already exists", name)
+	}
+	return d.Queries.CreateColumn(ctx, name)
+}
+
+// RenameColumn renames a board column, rejecting names that are already
+// taken by another column.
+func (d *Database) RenameColumn(ctx context.Context, id int, name string) error {
+	if column, err := d.Queries.GetColumnByName(ctx, name); err == nil && column.ID != id {
+		return fmt.Errorf("column %q already exists", name)
+	}
+	return d.Queries.RenameColumn(ctx, orm.RenameColumnParams{ID: id, Name: name})
+}
+
+// MoveToColumn puts a todo in a board column.
+func (d *Database) MoveToColumn(ctx context.Context, id int, columnID int) error {
+	return d.Queries.SetTodoColumn(ctx, orm.SetTodoColumnParams{
+		ID:        id,
+		ColumnID:  nullID(columnID),
+		UpdatedAt: time.Now(),
+	})
+}
+
+// ColumnCounts counts the active todos matching the filter in each board
+// column, in the same order as columns. Todos without a column, or whose
+// column is gone, count toward the first. The board passes the filter of the
+// tab it shows, so WIP limits are checked against the cards on screen.
+func (d *Database) ColumnCounts(ctx context.Context, columns []orm.BoardColumn, f Filter) ([]int, error) {
+	counts := make([]int, len(columns))
+	if len(columns) == 0 {
+		return counts, nil
+	}
+	query, args := f.SQL()
+	rows, err := d.db.QueryContext(ctx, "SELECT column_id, COUNT(*) FROM todos WHERE completed = FALSE AND id IN ("+query+") GROUP BY column_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var column sql.NullInt64
+		var count int
+		if err := rows.Scan(&column, &count); err != nil {
+			return nil, err
+		}
+		counts[columnIndex(columns, column)] += count
+	}
+	return counts, rows.Err()
+}
+
+// columnIndex returns the index of the column with the given id, falling back
+// to the first column.
+func columnIndex(columns []orm.BoardColumn, id sql.NullInt64) int {
+	if !id.Valid {
+		return 0
+	}
+	for i, c := range columns {
+		if int64(c.ID) == id.Int64 {
+			return i
+		}
+	}
+	return 0
+}
+
+// overLimit reports whether a column holds more cards than its WIP limit
+// allows.
+func overLimit(column orm.BoardColumn, count int) bool {
+	return column.WipLimit > 0 && count > column.WipLimit
+}
+
+// boardCards splits todos into the board's columns, as indexes into todos so
+// the list cursor can double as the board cursor.
+func boardCards(todos []orm.Todo, columns []orm.BoardColumn) [][]int {
+	cards := make([][]int, len(columns))
+	if len(columns) == 0 {
+		return cards
+	}
+	for i, todo := range todos {
+		c := columnIndex(columns, todo.ColumnID)
+		cards[c] = append(cards[c], i)
+	}
+	return cards
+}
+
+// boardActive reports whether the active list is being shown as a board.
+func (s State) boardActive() bool {
+	return s.showBoard && s.listingActive() && len(s.columns) > 0
+}
+
+// boardPosition locates the cursor's card as a column and a row within it.
+func (s State) boardPosition(cards [][]int) (int, int) {
+	for c, column := range cards {
+		for r, i := range column {
+			if i == s.cursor {
+				return c, r
+			}
+		}
+	}
+	return 0, 0
+}
+
+// moveBoardColumn moves the cursor to the nearest column in direction dir
+// that has cards, keeping the row where it can.
+func (s State) moveBoardColumn(dir int) State {
+	cards := boardCards(s.todos, s.columns)
+	c, r := s.boardPosition(cards)
+	for next := c + dir; next >= 0 && next < len(cards); next += dir {
+		if len(cards[next]) > 0 {
+			s.cursor = cards[next][min(r, len(cards[next])-1)]
+			break
+		}
+	}
+	return s
+}
+
+// moveBoardRow moves the cursor up or down within its column.
+func (s State) moveBoardRow(dir int) State {
+	cards := boardCards(s.todos, s.columns)
+	c, r := s.boardPosition(cards)
+	if next := r + dir; next >= 0 && next < len(cards[c]) {
+		s.cursor = cards[c][next]
+	}
+	return s
+}
+
+// shiftCard moves the selected card to the adjacent column in direction dir.
+func (s State) shiftCard(dir int) tea.Cmd {
+	cards := boardCards(s.todos, s.columns)
+	c, _ := s.boardPosition(cards)
+	target := c + dir
+	if target < 0 || target >= len(s.columns) {
+		return nil
+	}
+	todo := s.todos[s.cursor]
+	column := s.columns[target]
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.MoveToColumn(ctx, todo.ID, column.ID); err != nil {
+			return tea.Msg(fmt.Sprintf("Error moving card: %v", err))
+		}
+		filter, err := s.listFilter(s.views)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error %v", err))
+		}
+		counts, err := s.database.ColumnCounts(ctx, s.columns, filter)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error counting cards: %v", err))
+		}
+		msg := cardMovedMsg{id: todo.ID}
+		if overLimit(column, counts[target]) {
+			msg.warning = fmt.Sprintf("⚠ %s is over its WIP limit (%d/%d)", column.Name, counts[target], column.WipLimit)
+		}
+		return msg
+	})
+}
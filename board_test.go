@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func TestParseWipLimit(t *testing.T) {
+	valid := map[string]int{"": 0, " ": 0, "0": 0, "3": 3, " 12 ": 12}
+	for in, want := range valid {
+		got, err := ParseWipLimit(in)
+		if err != nil || got != want {
+			t.Errorf("ParseWipLimit(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"-1", "two", "1.5", "3 cards"} {
+		if _, err := ParseWipLimit(in); err == nil {
+			t.Errorf("ParseWipLimit(%q) succeeded; want an error", in)
+		}
+	}
+}
+
+func TestNormalizeColumnName(t *testing.T) {
+	if got, err := NormalizeColumnName("  in   review "); err != nil || got != "in review" {
+		t.Errorf("NormalizeColumnName = %q, %v; want %q", got, err, "in review")
+	}
+	for _, in := range []string{"", "   ", "a column name far too long"} {
+		if _, err := NormalizeColumnName(in); err == nil {
+			t.Errorf("NormalizeColumnName(%q) succeeded; want an error", in)
+		}
+	}
+}
+
+// TestManageColumns checks that columns can be added, renamed, limited, and
+// deleted, and that a deleted column's cards fall back to the first column.
+func TestManageColumns(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	columnNames := func() []string {
+		t.Helper()
+		columns, err := d.Queries.ListColumns(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, c := range columns {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	done, err := d.CreateColumn(ctx, "done")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.CreateColumn(ctx, "done"); err == nil {
+		t.Error("created a second column named done")
+	}
+	if got, want := columnNames(), []string{"backlog", "doing", "review", "done"}; !slices.Equal(got, want) {
+		t.Fatalf("columns = %v; want %v", got, want)
+	}
+
+	if err := d.RenameColumn(ctx, done.ID, "review"); err == nil {
+		t.Error("renamed done to the taken name review")
+	}
+	if err := d.RenameColumn(ctx, done.ID, "done"); err != nil {
+		t.Errorf("renaming a column to its own name: %v", err)
+	}
+	if err := d.RenameColumn(ctx, done.ID, "shipped"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := columnNames(), []string{"backlog", "doing", "review", "shipped"}; !slices.Equal(got, want) {
+		t.Fatalf("columns after rename = %v; want %v", got, want)
+	}
+
+	todos := createTestTodos(t, d, "a", "b", "c")
+	for _, todo := range todos {
+		if err := d.MoveToColumn(ctx, todo.ID, done.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Queries.SetColumnWipLimit(ctx, orm.SetColumnWipLimitParams{ID: done.ID, WipLimit: 2}); err != nil {
+		t.Fatal(err)
+	}
+	columns, err := d.Queries.ListColumns(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := d.ColumnCounts(ctx, columns, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(counts, []int{0, 0, 0, 3}) || !overLimit(columns[3], counts[3]) {
+		t.Errorf("counts = %v with limit %d; want shipped over its limit with 3", counts, columns[3].WipLimit)
+	}
+	// A board showing one project counts only that project's cards.
+	project, err := d.CreateProject(ctx, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Queries.MoveTodoToProject(ctx, orm.MoveTodoToProjectParams{ProjectID: nullID(project.ID), UpdatedAt: time.Now(), ID: todos[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter, err := State{viewMode: ProjectView, projectID: project.ID}.listFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err = d.ColumnCounts(ctx, columns, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(counts, []int{0, 0, 0, 1}) || overLimit(columns[3], counts[3]) {
+		t.Errorf("counts for the project = %v; want shipped under its limit with 1", counts)
+	}
+
+	if err := d.Queries.DeleteColumn(ctx, done.ID); err != nil {
+		t.Fatal(err)
+	}
+	columns, err = d.Queries.ListColumns(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err = d.ColumnCounts(ctx, columns, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(counts, []int{3, 0, 0}) {
+		t.Errorf("counts after delete = %v; want every card back in backlog", counts)
+	}
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// standalone commands manage their own database and are passed nil
+	standalone bool
+	run        func(db *Database, args []string) error
+}
+
+const (
+	addUsage    = "add <content> [--priority P0|P1|P2]"
+	listUsage   = "list [--completed | --all] [--format text|json|ndjson]"
+	doneUsage   = "done <id>"
+	editUsage   = "edit <id> <content>"
+	rmUsage     = "rm <id>"
+	reportUsage = "report [--by day|tag] [--days N]"
+	initUsage   = "init [dir]"
+)
+
+var commands = []command{
+	{name: "add", usage: addUsage, summary: "create a new todo", run: runAdd},
+	{name: "list", usage: listUsage, summary: "list todos", run: runList},
+	{name: "done", usage: doneUsage, summary: "mark a todo as done", run: runDone},
+	{name: "edit", usage: editUsage, summary: "replace a todo's content", run: runEdit},
+	{name: "rm", usage: rmUsage, summary: "move a todo to the trash", run: runRm},
+	{name: "report", usage: reportUsage, summary: "sum tracked time per day or per tag", run: runReport},
+	{name: "init", usage: initUsage, summary: "create a repository todo list in dir/.godoit", standalone: true, run: runInit},
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: godoit [flags] [command] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "with no command, godoit opens the interactive todo list. inside a")
+	fmt.Fprintln(w, "directory tree containing .godoit/todos.db (see init), that list is")
+	fmt.Fprintln(w, "used instead of the global one.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.usage, c.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "flags:")
+	flag.CommandLine.SetOutput(w)
+	flag.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "environment:")
+	fmt.Fprintln(w, "  GODOIT_DB          database file to use when neither --db nor --profile is given")
+	fmt.Fprintln(w, "  GODOIT_TRASH_DAYS  days to keep deleted todos when --trash-days is not given")
+	fmt.Fprintln(w, "  XDG_DATA_HOME      base directory for the default database")
+}
+
+// runCommand dispatches a subcommand by name. It opens the database only
+// once the command is known so typos don't create an empty todos.db.
+func runCommand(dbPath string, trashDays int, args []string) error {
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(os.Stdout)
+		return nil
+	}
+	c, ok := findCommand(args[0])
+	if !ok {
+		usage(os.Stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	if c.standalone {
+		err := c.run(nil, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	db, err := NewDatabase(dbPath, trashDays)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	err = c.run(db, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: godoit %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses flags that may appear before, between, or after the
+// positional arguments, so `add "text" --priority P0` works as expected.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func parsePriority(s string) (Priority, error) {
+	switch p := Priority(strings.ToUpper(s)); p {
+	case P0, P1, P2:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid priority %q (want P0, P1, or P2)", s)
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid todo id %q", s)
+	}
+	return id, nil
+}
+
+// lookupTodo fetches a todo by id, turning a missing row into a readable
+// error. Trashed todos are treated as missing.
+func lookupTodo(ctx context.Context, db *Database, id int) (orm.Todo, error) {
+	todo, err := db.Queries.GetTodo(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todo, fmt.Errorf("no todo with id %d", id)
+	}
+	if err == nil && todo.DeletedAt.Valid {
+		return todo, fmt.Errorf("todo #%d is in the trash", id)
+	}
+	return todo, err
+}
+
+func runAdd(db *Database, args []string) error {
+	fs := newFlagSet("add", addUsage)
+	priority := fs.String("priority", string(P2), "priority of the new todo")
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSpace(strings.Join(rest, " "))
+	if content == "" {
+		fs.Usage()
+		return errors.New("add: missing todo content")
+	}
+	p, err := parsePriority(*priority)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	now := time.Now()
+	todo, err := db.CreateTodo(ctx, orm.CreateTodoParams{
+		Content:   content,
+		Priority:  string(p),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("creating todo: %w", err)
+	}
+	fmt.Printf("added #%d\n", todo.ID)
+	return nil
+}
+
+func runList(db *Database, args []string) error {
+	fs := newFlagSet("list", listUsage)
+	completed := fs.Bool("completed", false, "list completed todos instead of active ones")
+	all := fs.Bool("all", false, "list both active and completed todos")
+	format := fs.String("format", "text", "output format: text, json, or ndjson")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	now := time.Now()
+	var todos []orm.Todo
+	if !*completed || *all {
+		active, err := db.ListTodos(ctx, false, Filter{}, defaultSort(false), now, 0, 0)
+		if err != nil {
+			return fmt.Errorf("listing todos: %w", err)
+		}
+		todos = append(todos, active...)
+	}
+	if *completed || *all {
+		done, err := db.ListTodos(ctx, true, Filter{}, defaultSort(true), now, 0, 0)
+		if err != nil {
+			return fmt.Errorf("listing todos: %w", err)
+		}
+		todos = append(todos, done...)
+	}
+	l, err := newListing(ctx, db, todos)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "text":
+		return writeText(os.Stdout, l)
+	case "json":
+		return writeJSON(os.Stdout, l)
+	case "ndjson":
+		return writeNDJSON(os.Stdout, l)
+	}
+	return fmt.Errorf("invalid format %q (want text, json, or ndjson)", *format)
+}
+
+// listing holds todos along with the lookups needed to print their tags and
+// project names.
+type listing struct {
+	todos    []orm.Todo
+	tags     map[int][]string
+	projects []orm.Project
+	columns  []orm.BoardColumn
+	blockers map[int][]int
+	tracked  map[int]time.Duration
+	timer    *orm.TimeEntry
+}
+
+func newListing(ctx context.Context, db *Database, todos []orm.Todo) (listing, error) {
+	ids := todoIDs(todos)
+	tags, err := db.TodoTags(ctx, ids)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing tags: %w", err)
+	}
+	projects, err := db.Queries.ListProjects(ctx)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing projects: %w", err)
+	}
+	columns, err := db.Queries.ListColumns(ctx)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing columns: %w", err)
+	}
+	blockers, err := db.OpenBlockers(ctx, ids)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing dependencies: %w", err)
+	}
+	tracked, err := db.TrackedTime(ctx, ids)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing tracked time: %w", err)
+	}
+	timer, err := db.RunningTimer(ctx)
+	if err != nil {
+		return listing{}, fmt.Errorf("listing timers: %w", err)
+	}
+	return listing{todos: todos, tags: tags, projects: projects, columns: columns, blockers: blockers, tracked: tracked, timer: timer}, nil
+}
+
+func (l listing) todo(t orm.Todo) Todo {
+	todo := NewTodo(t)
+	todo.Tags = append(todo.Tags, l.tags[t.ID]...)
+	if name := projectName(l.projects, t.ProjectID); name != "" {
+		todo.Project = &name
+	}
+	if !t.Completed {
+		todo.BlockedBy = append(todo.BlockedBy, l.blockers[t.ID]...)
+	}
+	if len(l.columns) > 0 && !t.Completed {
+		name := l.columns[columnIndex(l.columns, t.ColumnID)].Name
+		todo.Column = &name
+	}
+	todo.TrackedSeconds = int64(l.trackedTime(t.ID).Seconds())
+	return todo
+}
+
+// trackedTime is a todo's total tracked time, including its running timer.
+func (l listing) trackedTime(id int) time.Duration {
+	total := l.tracked[id]
+	if l.timer != nil && l.timer.TodoID == id {
+		total += elapsed(*l.timer, time.Now())
+	}
+	return total
+}
+
+func writeText(w io.Writer, l listing) error {
+	now := time.Now()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, todo := range l.todos {
+		mark := " "
+		if todo.Completed {
+			mark = "x"
+		}
+		due := ""
+		if todo.DueAt.Valid {
+			due = "(due " + formatDue(todo.DueAt.Time, now)
+			if !todo.Completed && dueStatus(todo.DueAt, now) == Overdue {
+				due += ", overdue"
+			}
+			due += ")"
+		}
+		line := []string{todo.Content}
+		if blockers := l.blockers[todo.ID]; len(blockers) > 0 && !todo.Completed {
+			line = append(line, "(blocked by "+formatBlockers(blockers)+")")
+		} else if s := Status(todo.Status); s == StatusDoing || s == StatusBlocked {
+			line = append(line, "("+string(s)+")")
+		}
+		if name := projectName(l.projects, todo.ProjectID); name != "" {
+			line = append(line, "@"+name)
+		}
+		for _, tag := range l.tags[todo.ID] {
+			line = append(line, "#"+tag)
+		}
+		if due != "" {
+			line = append(line, due)
+		}
+		if todo.Recurrence != "" {
+			if r, err := ParseRecurrence(todo.Recurrence); err == nil {
+				line = append(line, "(repeats "+r.Describe()+")")
+			}
+		}
+		if tracked := l.trackedTime(todo.ID); tracked > 0 {
+			line = append(line, "(tracked "+formatTracked(tracked)+")")
+		}
+		fmt.Fprintf(tw, "%d\t[%s]\t%s\t%s\n", todo.ID, mark, todo.Priority, strings.Join(line, " "))
+	}
+	return tw.Flush()
+}
+
+// writeJSON prints todos as a single indented JSON array. An empty list is
+// written as [] rather than null so consumers never need a special case.
+func writeJSON(w io.Writer, l listing) error {
+	out := make([]Todo, 0, len(l.todos))
+	for _, todo := range l.todos {
+		out = append(out, l.todo(todo))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// writeNDJSON prints one compact JSON object per line.
+func writeNDJSON(w io.Writer, l listing) error {
+	enc := json.NewEncoder(w)
+	for _, todo := range l.todos {
+		if err := enc.Encode(l.todo(todo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDone(db *Database, args []string) error {
+	fs := newFlagSet("done", doneUsage)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		fs.Usage()
+		return errors.New("done: expected exactly one todo id")
+	}
+	id, err := parseID(rest[0])
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	todo, err := lookupTodo(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	if todo.Completed {
+		return fmt.Errorf("todo #%d is already done", id)
+	}
+	next, err := db.ToggleTodo(ctx, id)
+	if err != nil {
+		return fmt.Errorf("completing todo: %w", err)
+	}
+	fmt.Printf("completed #%d\n", id)
+	if next != nil {
+		fmt.Printf("next occurrence #%d due %s\n", next.ID, formatDue(next.DueAt.Time, time.Now()))
+	}
+	return nil
+}
+
+func runEdit(db *Database, args []string) error {
+	fs := newFlagSet("edit", editUsage)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) < 2 {
+		fs.Usage()
+		return errors.New("edit: expected a todo id and new content")
+	}
+	id, err := parseID(rest[0])
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSpace(strings.Join(rest[1:], " "))
+	if content == "" {
+		return errors.New("edit: content must not be empty")
+	}
+	ctx := context.Background()
+	if _, err := lookupTodo(ctx, db, id); err != nil {
+		return err
+	}
+	if err := db.UpdateContent(ctx, id, content); err != nil {
+		return fmt.Errorf("updating todo: %w", err)
+	}
+	fmt.Printf("updated #%d\n", id)
+	return nil
+}
+
+func runRm(db *Database, args []string) error {
+	fs := newFlagSet("rm", rmUsage)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		fs.Usage()
+		return errors.New("rm: expected exactly one todo id")
+	}
+	id, err := parseID(rest[0])
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if _, err := lookupTodo(ctx, db, id); err != nil {
+		return err
+	}
+	if err := db.Trash(ctx, id); err != nil {
+		return fmt.Errorf("deleting todo: %w", err)
+	}
+	fmt.Printf("moved #%d to the trash\n", id)
+	return nil
+}
+
+func runInit(_ *Database, args []string) error {
+	fs := newFlagSet("init", initUsage)
+	rest, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		fs.Usage()
+		return errors.New("init: expected at most one directory")
+	}
+	dir := "."
+	if len(rest) == 1 {
+		dir = rest[0]
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	path := RepoDBPath(root)
+	_, statErr := os.Stat(path)
+	// init only creates or migrates the list, so it leaves the trash alone.
+	db, err := NewDatabase(path, 0)
+	if err != nil {
+		return fmt.Errorf("initializing %s: %w", path, err)
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+	if statErr == nil {
+		fmt.Printf("reinitialized existing todo list in %s\n", path)
+	} else {
+		fmt.Printf("initialized empty todo list in %s\n", path)
+	}
+	return nil
+}
+
+// runReport prints tracked time per day or per tag over the last few days,
+// counting a running timer up to now.
+func runReport(db *Database, args []string) error {
+	fs := newFlagSet("report", reportUsage)
+	byName := fs.String("by", "day", "group tracked time by day or tag")
+	days := fs.Int("days", 7, "how many days back to report, including today (0 for all time)")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	by, err := ParseReportBy(*byName)
+	if err != nil {
+		return err
+	}
+	if *days < 0 {
+		return fmt.Errorf("invalid --days %d (want 0 or more)", *days)
+	}
+	now := time.Now()
+	var since time.Time
+	if *days > 0 {
+		since = startOfDay(now).AddDate(0, 0, 1-*days)
+	}
+	rows, err := db.TimeReport(context.Background(), by, since, now)
+	if err != nil {
+		return fmt.Errorf("building report: %w", err)
+	}
+	if len(rows) == 0 {
+		fmt.Println("no time tracked")
+		return nil
+	}
+	var total time.Duration
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%7s\n", row.key, formatTracked(row.total))
+		total += row.total
+	}
+	if by == ReportByDay {
+		fmt.Fprintf(tw, "total\t%7s\n", formatTracked(total))
+	}
+	return tw.Flush()
+}
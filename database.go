@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
@@ -19,21 +22,24 @@ var embedMigrations embed.FS
 
 type Database struct {
 	db      *sql.DB
+	Path    string
 	Queries *orm.Queries
+	// TrashDays is how long trashed todos are kept, where 0 keeps them
+	// forever.
+	TrashDays int
 }
 
-func NewDatabase() (*Database, error) {
-	home, err := os.UserHomeDir()
+// NewDatabase opens and migrates the database at dbPath, then purges todos
+// that have been in the trash longer than trashDays.
+func NewDatabase(dbPath string, trashDays int) (*Database, error) {
+	err := os.MkdirAll(filepath.Dir(dbPath), 0755)
 	if err != nil {
 		return nil, err
 	}
-	localShareGodoit := filepath.Join(home, ".local", "share", "godoit")
-	err = os.MkdirAll(localShareGodoit, 0755)
-	if err != nil {
-		return nil, err
-	}
-	dbPath := filepath.Join(localShareGodoit, "todos.db")
-	sqlDB, err := sql.Open("sqlite3", dbPath)
+	// foreign keys are off by default in sqlite; todo_tags relies on cascades.
+	// The path goes in a file: URI so a ? or # in it isn't read as options.
+	dsn := url.URL{Scheme: "file", Path: dbPath, RawQuery: "_foreign_keys=on"}
+	sqlDB, err := sql.Open("sqlite3", dsn.String())
 	if err != nil {
 		return nil, err
 	}
@@ -45,15 +51,24 @@ func NewDatabase() (*Database, error) {
 
 	goose.SetBaseFS(embedMigrations)
 	if err := goose.SetDialect("sqlite3"); err != nil {
+		sqlDB.Close()
 		return nil, err
 	}
 	if err := goose.Up(sqlDB, "migrations"); err != nil {
-		log.Fatalf("failed to migrate todos.db")
+		sqlDB.Close()
+		return nil, fmt.Errorf("migrating %s: %w", dbPath, err)
+	}
+	d := &Database{
+		db:        sqlDB,
+		Path:      dbPath,
+		Queries:   orm.New(sqlDB),
+		TrashDays: trashDays,
 	}
-	return &Database{
-		db:      sqlDB,
-		Queries: orm.New(sqlDB),
-	}, nil
+	if _, err := d.PurgeExpiredTrash(context.Background(), d.TrashDays, time.Now()); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("purging trash: %w", err)
+	}
+	return d, nil
 }
 
 func (d *Database) Close() error {
@@ -69,10 +84,64 @@ const (
 )
 
 type Todo struct {
-	ID        int       `json:"id"`
-	Content   string    `json:"content"`
-	Priority  Priority  `json:"priority"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID             int        `json:"id"`
+	Content        string     `json:"content"`
+	Priority       Priority   `json:"priority"`
+	Completed      bool       `json:"completed"`
+	Status         Status     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DueAt          *time.Time `json:"due_at"`
+	Tags           []string   `json:"tags"`
+	Project        *string    `json:"project"`
+	Column         *string    `json:"column"`
+	BlockedBy      []int      `json:"blocked_by"`
+	TrackedSeconds int64      `json:"tracked_seconds"`
+	ParentID       *int       `json:"parent_id"`
+	Notes          string     `json:"notes"`
+	Recurrence     string     `json:"recurrence"`
+	CompletedAt    *time.Time `json:"completed_at"`
+}
+
+// NewTodo converts a database row into the Todo used for serialization. Tags
+// and the project name live in other tables and are filled in by the caller.
+func NewTodo(t orm.Todo) Todo {
+	var dueAt *time.Time
+	if t.DueAt.Valid {
+		dueAt = &t.DueAt.Time
+	}
+	var completedAt *time.Time
+	if t.CompletedAt.Valid {
+		completedAt = &t.CompletedAt.Time
+	}
+	var parentID *int
+	if t.ParentID.Valid {
+		id := int(t.ParentID.Int64)
+		parentID = &id
+	}
+	return Todo{
+		ID:          t.ID,
+		Content:     t.Content,
+		Priority:    Priority(t.Priority),
+		Completed:   t.Completed,
+		Status:      Status(t.Status),
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		DueAt:       dueAt,
+		Tags:        []string{},
+		BlockedBy:   []int{},
+		ParentID:    parentID,
+		Notes:       t.Notes,
+		Recurrence:  t.Recurrence,
+		CompletedAt: completedAt,
+	}
+}
+
+// nullID converts an optional row id, where 0 means none, into a nullable
+// foreign key value.
+func nullID(id int) sql.NullInt64 {
+	if id == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(id), Valid: true}
 }
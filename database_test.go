@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestDatabase opens a freshly migrated database that is removed when
+// the test ends.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	d, err := NewDatabase(filepath.Join(t.TempDir(), "todos.db"), DefaultTrashDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// TestNewDatabasePath checks that a path with characters that mean something
+// in a URI opens the file it names, with foreign keys enforced.
+func TestNewDatabasePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "my todos?#1%.db")
+	d, err := NewDatabase(path, DefaultTrashDays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("database not created at %q: %v", path, err)
+	}
+	var on bool
+	if err := d.db.QueryRow("PRAGMA foreign_keys").Scan(&on); err != nil || !on {
+		t.Errorf("foreign_keys = %t, %v; want on", on, err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// Link records that id can't start until blocker is done. Links that would
+// close a loop are rejected, since no todo in it could ever start. An open
+// todo that gains an open blocker moves to the blocked status.
+func (d *Database) Link(ctx context.Context, id, blocker int) error {
+	if id == blocker {
+		return errors.New("a todo can't block itself")
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	loops, err := q.CountDependencyPath(ctx, orm.CountDependencyPathParams{FromID: blocker, ToID: id})
+	if err != nil {
+		return err
+	}
+	if loops > 0 {
+		return fmt.Errorf("#%d already waits on #%d, so this would create a cycle", blocker, id)
+	}
+	if err := q.AddDependency(ctx, orm.AddDependencyParams{TodoID: id, BlockerID: blocker}); err != nil {
+		return err
+	}
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	b, err := q.GetTodo(ctx, blocker)
+	if err != nil {
+		return err
+	}
+	if Status(todo.Status) == StatusTodo && !b.Completed && !b.DeletedAt.Valid {
+		if err := setStatusLogged(ctx, q, todo, StatusBlocked, time.Now()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Unlink removes a blocked-by link, unblocking id if nothing else holds it
+// up.
+func (d *Database) Unlink(ctx context.Context, id, blocker int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	if err := q.RemoveDependency(ctx, orm.RemoveDependencyParams{TodoID: id, BlockerID: blocker}); err != nil {
+		return err
+	}
+	if err := releaseIfUnblocked(ctx, q, id, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// OpenBlockers maps each of the given todos that is waiting on something to
+// the ids of its blockers that are still open.
+func (d *Database) OpenBlockers(ctx context.Context, ids []int) (map[int][]int, error) {
+	rows, err := d.Queries.GetOpenBlockers(ctx, idList(ids))
+	if err != nil {
+		return nil, err
+	}
+	blockers := make(map[int][]int)
+	for _, row := range rows {
+		blockers[row.TodoID] = append(blockers[row.TodoID], row.BlockerID)
+	}
+	return blockers, nil
+}
+
+// unblockDependents moves todos that were waiting on blocker, and now wait on
+// nothing, from blocked back to todo. It runs whenever blocker is completed
+// or moved to the trash.
+func unblockDependents(ctx context.Context, q *orm.Queries, blocker int, now time.Time) error {
+	dependents, err := q.GetDependents(ctx, blocker)
+	if err != nil {
+		return err
+	}
+	for _, id := range dependents {
+		if err := releaseIfUnblocked(ctx, q, id, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockDependents moves todos waiting on blocker from todo back to blocked.
+// It runs whenever blocker is reopened or restored, undoing
+// unblockDependents.
+func blockDependents(ctx context.Context, q *orm.Queries, blocker int, now time.Time) error {
+	dependents, err := q.GetDependents(ctx, blocker)
+	if err != nil {
+		return err
+	}
+	for _, id := range dependents {
+		if err := blockIfWaiting(ctx, q, id, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func releaseIfUnblocked(ctx context.Context, q *orm.Queries, id int, now time.Time) error {
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	if Status(todo.Status) != StatusBlocked {
+		return nil
+	}
+	open, err := q.CountOpenBlockers(ctx, id)
+	if err != nil || open > 0 {
+		return err
+	}
+	return setStatusLogged(ctx, q, todo, StatusTodo, now)
+}
+
+func blockIfWaiting(ctx context.Context, q *orm.Queries, id int, now time.Time) error {
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	if Status(todo.Status) != StatusTodo || todo.DeletedAt.Valid {
+		return nil
+	}
+	open, err := q.CountOpenBlockers(ctx, id)
+	if err != nil || open == 0 {
+		return err
+	}
+	return setStatusLogged(ctx, q, todo, StatusBlocked, now)
+}
+
+// linkPickLimit caps how many todos the link picker lists at once. Typing
+// narrows the list, so there's no need to read every open todo.
+const linkPickLimit = 50
+
+// LinkCandidates lists the open todos that id could be blocked by: any but
+// itself and its current blockers, matching query by content or #id.
+func (d *Database) LinkCandidates(ctx context.Context, id int, query string) ([]orm.Todo, error) {
+	f := Filter{}.
+		and("id != ?", id).
+		and("id NOT IN (SELECT blocker_id FROM todo_dependencies WHERE todo_id = ?)", id)
+	if query = strings.TrimSpace(query); query != "" {
+		ref := 0
+		if strings.HasPrefix(query, "#") {
+			ref, _ = strconv.Atoi(query[1:])
+		}
+		f = f.and(`(content LIKE ? ESCAPE '\' OR id = ?)`, "%"+escapeLike(query)+"%", ref)
+	}
+	return d.ListTodos(ctx, false, f, defaultSort(false), time.Now(), 0, linkPickLimit)
+}
+
+// unblocked is the condition that a todo waits on no open blocker, matching
+// GetOpenBlockers.
+const unblocked = "NOT EXISTS (SELECT 1 FROM todo_dependencies AS dep JOIN todos AS blocker ON blocker.id = dep.blocker_id WHERE dep.todo_id = todos.id AND blocker.completed = FALSE AND blocker.deleted_at IS NULL)"
+
+// formatBlockers renders blocker ids as "#12, #14".
+func formatBlockers(ids []int) string {
+	refs := make([]string, len(ids))
+	for i, id := range ids {
+		refs[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(refs, ", ")
+}
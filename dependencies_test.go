@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func createTestTodos(t *testing.T, d *Database, contents ...string) []orm.Todo {
+	t.Helper()
+	var todos []orm.Todo
+	for _, content := range contents {
+		todo, err := d.Queries.CreateTodo(context.Background(), orm.CreateTodoParams{
+			Content:   content,
+			Priority:  string(P2),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+func TestLinkRejectsCycles(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todos := createTestTodos(t, d, "design", "build", "ship", "celebrate")
+	a, b, c, e := todos[0].ID, todos[1].ID, todos[2].ID, todos[3].ID
+	// build waits on design, ship on build.
+	for _, link := range [][2]int{{b, a}, {c, b}} {
+		if err := d.Link(ctx, link[0], link[1]); err != nil {
+			t.Fatalf("Link(%d, %d): %v", link[0], link[1], err)
+		}
+	}
+	tests := []struct {
+		id, blocker int
+		ok          bool
+	}{
+		{a, a, false},
+		{a, b, false}, // a direct loop
+		{a, c, false}, // a loop through build
+		{c, a, true},  // already implied, but no loop
+		{e, c, true},
+		{a, e, false}, // celebrate now waits on design through ship
+	}
+	for _, tt := range tests {
+		err := d.Link(ctx, tt.id, tt.blocker)
+		if (err == nil) != tt.ok {
+			t.Errorf("Link(%d, %d) = %v, want ok %t", tt.id, tt.blocker, err, tt.ok)
+		}
+	}
+}
+
+func TestBlockedStatusFollowsBlocker(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todos := createTestTodos(t, d, "blocker", "dependent")
+	blocker, dependent := todos[0].ID, todos[1].ID
+	status := func() Status {
+		t.Helper()
+		todo, err := d.Queries.GetTodo(ctx, dependent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Status(todo.Status)
+	}
+	if err := d.Link(ctx, dependent, blocker); err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		name string
+		do   func() error
+		want Status
+	}{
+		{"trash blocker", func() error { return d.Trash(ctx, blocker) }, StatusTodo},
+		{"restore blocker", func() error { return d.Restore(ctx, blocker) }, StatusBlocked},
+		{"complete blocker", func() error { _, err := d.ToggleTodo(ctx, blocker); return err }, StatusTodo},
+		{"reopen blocker", func() error { _, err := d.ToggleTodo(ctx, blocker); return err }, StatusBlocked},
+		{"trash dependent", func() error { return d.Trash(ctx, dependent) }, StatusBlocked},
+		{"trash blocker too", func() error { return d.Trash(ctx, blocker) }, StatusTodo},
+		{"restore dependent alone", func() error { return d.Restore(ctx, dependent) }, StatusTodo},
+		{"restore blocker after", func() error { return d.Restore(ctx, blocker) }, StatusBlocked},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := status(); got != step.want {
+			t.Errorf("after %s, dependent is %s, want %s", step.name, got, step.want)
+		}
+	}
+}
+
+func TestLinkCandidates(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	var contents []string
+	for i := range linkPickLimit + 10 {
+		contents = append(contents, fmt.Sprintf("task %d", i))
+	}
+	todos := createTestTodos(t, d, append(contents, "Deploy 100%", "deploy docs")...)
+	self, blocker, done := todos[0].ID, todos[1].ID, todos[2].ID
+	if err := d.Link(ctx, self, blocker); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ToggleTodo(ctx, done); err != nil {
+		t.Fatal(err)
+	}
+	all, err := d.LinkCandidates(ctx, self, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != linkPickLimit {
+		t.Errorf("listed %d todos, want the limit of %d", len(all), linkPickLimit)
+	}
+	for _, id := range []int{self, blocker, done} {
+		if slices.Contains(todoIDs(all), id) {
+			t.Errorf("#%d is offered as a blocker", id)
+		}
+	}
+	deploy := todos[len(todos)-2].ID
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"DEPLOY 100%", []int{deploy}},
+		{fmt.Sprintf("#%d", deploy), []int{deploy}},
+		{fmt.Sprintf("#%d", blocker), nil},
+		{"100_", nil},
+	}
+	for _, tt := range tests {
+		got, err := d.LinkCandidates(ctx, self, tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(todoIDs(got), tt.want) && len(got)+len(tt.want) > 0 {
+			t.Errorf("LinkCandidates(%q) = %v, want %v", tt.query, todoIDs(got), tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var relativeDuePattern = regexp.MustCompile(`^\+?(\d+)\s*([dwmy])$`)
+
+// dueLayouts are the absolute date formats accepted by ParseDue. Layouts
+// without a year resolve to the next matching date.
+var dueLayouts = []struct {
+	layout  string
+	hasYear bool
+}{
+	{"2006-01-02", true},
+	{"2006-01-02 15:04", true},
+	{"2006-01-02T15:04", true},
+	{"01/02/2006", true},
+	{"Jan 2 2006", true},
+	{"January 2 2006", true},
+	{"Jan 2", false},
+	{"January 2", false},
+	{"2 Jan", false},
+	{"01/02", false},
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from a to b, ignoring DST shifts.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// ParseDue turns user input into a due date relative to now. It accepts
+// today/tomorrow, weekday names (the next such day), offsets like +3d, 2w,
+// 1m, or 1y, and absolute dates such as 2025-06-01 or "Jun 1".
+func ParseDue(input string, now time.Time) (time.Time, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	today := startOfDay(now)
+	switch s {
+	case "today", "tod":
+		return today, nil
+	case "tomorrow", "tmr", "tom":
+		return today.AddDate(0, 0, 1), nil
+	case "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return today.AddDate(0, 1, 0), nil
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			days := (int(d) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+	if m := relativeDuePattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			return today.AddDate(0, 0, n), nil
+		case "w":
+			return today.AddDate(0, 0, 7*n), nil
+		case "m":
+			return today.AddDate(0, n, 0), nil
+		case "y":
+			return today.AddDate(n, 0, 0), nil
+		}
+	}
+	for _, l := range dueLayouts {
+		t, err := time.ParseInLocation(l.layout, strings.TrimSpace(input), now.Location())
+		if err != nil {
+			continue
+		}
+		if !l.hasYear {
+			t = nextDate(t, today)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("can't understand due date %q (try today, tomorrow, fri, +3d, or 2006-01-02)", input)
+}
+
+// nextDate moves a date parsed without a year into the first year, from
+// today's on, where it falls on or after today. Feb 29 waits for the next
+// leap year rather than rolling over into March.
+func nextDate(t, today time.Time) time.Time {
+	for y := today.Year(); ; y++ {
+		d := time.Date(y, t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+		if d.Day() == t.Day() && !d.Before(today) {
+			return d
+		}
+	}
+}
+
+// DueStatus classifies a due date relative to now.
+type DueStatus int
+
+const (
+	NoDue DueStatus = iota
+	DueLater
+	DueToday
+	Overdue
+)
+
+func dueStatus(due sql.NullTime, now time.Time) DueStatus {
+	if !due.Valid {
+		return NoDue
+	}
+	today := startOfDay(now)
+	switch {
+	case due.Time.Before(today):
+		return Overdue
+	case due.Time.Before(today.AddDate(0, 0, 1)):
+		return DueToday
+	default:
+		return DueLater
+	}
+}
+
+// formatDue renders a due date compactly for the list, e.g. "today",
+// "tomorrow", "fri", "jun 3", or "jun 3 2027" for other years.
+func formatDue(due time.Time, now time.Time) string {
+	today := startOfDay(now)
+	day := startOfDay(due.In(now.Location()))
+	var label string
+	switch days := daysBetween(today, day); {
+	case days == 0:
+		label = "today"
+	case days == 1:
+		label = "tomorrow"
+	case days == -1:
+		label = "yesterday"
+	case days > 1 && days < 7:
+		label = strings.ToLower(day.Format("Mon"))
+	case day.Year() == today.Year():
+		label = strings.ToLower(day.Format("Jan 2"))
+	default:
+		label = strings.ToLower(day.Format("Jan 2 2006"))
+	}
+	if !due.Equal(day) {
+		label += " " + due.Format("15:04")
+	}
+	return label
+}
+
+// completionGroup buckets a completion time under the headings used by the
+// completed tab: today, yesterday, this week, or earlier.
+func completionGroup(completedAt sql.NullTime, now time.Time) string {
+	if !completedAt.Valid {
+		return "earlier"
+	}
+	day := startOfDay(completedAt.Time.In(now.Location()))
+	switch days := daysBetween(day, startOfDay(now)); {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case !day.Before(weekStart(now)):
+		return "this week"
+	default:
+		return "earlier"
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	// A Wednesday afternoon.
+	now := time.Date(2026, time.June, 10, 14, 30, 0, 0, zone)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, zone)
+	}
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"today", day(2026, time.June, 10)},
+		{"  TOMORROW ", day(2026, time.June, 11)},
+		{"tmr", day(2026, time.June, 11)},
+		{"next week", day(2026, time.June, 17)},
+		{"next month", day(2026, time.July, 10)},
+		{"fri", day(2026, time.June, 12)},
+		{"Monday", day(2026, time.June, 15)},
+		{"wed", day(2026, time.June, 17)},
+		{"+3d", day(2026, time.June, 13)},
+		{"3 d", day(2026, time.June, 13)},
+		{"2w", day(2026, time.June, 24)},
+		{"1m", day(2026, time.July, 10)},
+		{"+1y", day(2027, time.June, 10)},
+		{"2026-07-04", day(2026, time.July, 4)},
+		{"2026-07-04 09:30", time.Date(2026, time.July, 4, 9, 30, 0, 0, zone)},
+		{"07/04/2026", day(2026, time.July, 4)},
+		{"Jul 4 2026", day(2026, time.July, 4)},
+		{"Jul 4", day(2026, time.July, 4)},
+		{"4 Jul", day(2026, time.July, 4)},
+		{"Jun 10", day(2026, time.June, 10)},
+		{"Jun 1", day(2027, time.June, 1)},
+		{"01/02", day(2027, time.January, 2)},
+		{"Feb 29", day(2028, time.February, 29)},
+		{"02/29", day(2028, time.February, 29)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDue(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseDue(%q): %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != zone {
+			t.Errorf("ParseDue(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+	for _, input := range []string{"", "someday", "+d", "-3d", "3h", "2026-13-01", "Feb 30"} {
+		if got, err := ParseDue(input, now); err == nil {
+			t.Errorf("ParseDue(%q) = %v, want an error", input, got)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// Kinds of todo_events rows. Each mutation below writes its event in the same
+// transaction as the change itself, so the history never disagrees with the
+// todo it describes.
+const (
+	EventCreate   = "create"
+	EventContent  = "content"
+	EventPriority = "priority"
+	EventToggle   = "toggle"
+	EventStatus   = "status"
+	EventDelete   = "delete"
+	EventRestore  = "restore"
+	EventPurge    = "purge"
+)
+
+func logEvent(ctx context.Context, q *orm.Queries, id int, kind, oldValue, newValue string, at time.Time) error {
+	return q.InsertTodoEvent(ctx, orm.InsertTodoEventParams{
+		TodoID:    id,
+		Kind:      kind,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		CreatedAt: at,
+	})
+}
+
+// completionValue is how a toggle event records a todo's completion.
+func completionValue(completed bool) string {
+	if completed {
+		return "done"
+	}
+	return "open"
+}
+
+// CreateTodo inserts a todo and records its creation.
+func (d *Database) CreateTodo(ctx context.Context, arg orm.CreateTodoParams) (orm.Todo, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return orm.Todo{}, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	todo, err := q.CreateTodo(ctx, arg)
+	if err != nil {
+		return orm.Todo{}, err
+	}
+	if err := logEvent(ctx, q, todo.ID, EventCreate, "", todo.Content, arg.CreatedAt); err != nil {
+		return orm.Todo{}, err
+	}
+	return todo, tx.Commit()
+}
+
+// UpdateContent rewrites a todo's content, recording the old and new text.
+func (d *Database) UpdateContent(ctx context.Context, id int, content string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = q.UpdateTodoContent(ctx, orm.UpdateTodoContentParams{
+		ID:        id,
+		Content:   content,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if err := logEvent(ctx, q, id, EventContent, todo.Content, content, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePriority changes a todo's priority, recording the old and new value.
+func (d *Database) UpdatePriority(ctx context.Context, id int, p Priority) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = q.UpdateTodoPriority(ctx, orm.UpdateTodoPriorityParams{
+		ID:        id,
+		Priority:  string(p),
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if err := logEvent(ctx, q, id, EventPriority, todo.Priority, string(p), now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Purge permanently deletes a todo and its subtasks. The todo's history is
+// kept, ending with the purge.
+func (d *Database) Purge(ctx context.Context, id int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	if err := q.DeleteTodo(ctx, id); err != nil {
+		return err
+	}
+	if err := logEvent(ctx, q, id, EventPurge, "", "", time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// EmptyTrash permanently deletes everything in the trash, returning how many
+// todos were removed.
+func (d *Database) EmptyTrash(ctx context.Context) (int64, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	trashed, err := q.GetTrashedTodos(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, todo := range trashed {
+		if err := logEvent(ctx, q, todo.ID, EventPurge, "", "", now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := q.EmptyTrash(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// toggle flips a todo's completion inside q's transaction. Completing a todo
+// stops its timer, unblocks the todos waiting on it and, when spawn is set,
+// creates its next occurrence if it recurs and doesn't have one already from
+// an earlier completion.
+func toggle(ctx context.Context, q *orm.Queries, id int, spawn bool, now time.Time) (*orm.Todo, error) {
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = q.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{
+		ID:          id,
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = logEvent(ctx, q, id, EventToggle, completionValue(todo.Completed), completionValue(!todo.Completed), now)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Completed {
+		if err := blockIfWaiting(ctx, q, id, now); err != nil {
+			return nil, err
+		}
+		return nil, blockDependents(ctx, q, id, now)
+	}
+	if err := stopTimer(ctx, q, id, now); err != nil {
+		return nil, err
+	}
+	if err := unblockDependents(ctx, q, id, now); err != nil {
+		return nil, err
+	}
+	if !spawn || todo.Recurrence == "" {
+		return nil, nil
+	}
+	spawned, err := q.CountNextOccurrences(ctx, nullID(id))
+	if err != nil || spawned > 0 {
+		return nil, err
+	}
+	return createNextOccurrence(ctx, q, todo, now)
+}
+
+// describeEvent summarizes an event for the history pane.
+func describeEvent(e orm.TodoEvent) string {
+	switch e.Kind {
+	case EventCreate:
+		return fmt.Sprintf("created %q", e.NewValue)
+	case EventContent:
+		return fmt.Sprintf("%q → %q", e.OldValue, e.NewValue)
+	case EventPriority, EventStatus:
+		return e.OldValue + " → " + e.NewValue
+	case EventToggle:
+		if e.NewValue == completionValue(true) {
+			return "completed"
+		}
+		return "reopened"
+	case EventDelete:
+		return "moved to trash"
+	case EventRestore:
+		return "restored from trash"
+	case EventPurge:
+		return "deleted forever"
+	}
+	return e.Kind
+}
+
+// eventContent returns the content a todo had right after e, if e changed
+// it.
+func eventContent(e orm.TodoEvent) (string, bool) {
+	if e.Kind == EventCreate || e.Kind == EventContent {
+		return e.NewValue, true
+	}
+	return "", false
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter is a parsed filter query such as
+//
+//	priority:P0,P1 created:<7d done:no text:"deploy"
+//
+// Each term narrows the list, so terms are ANDed while comma separated values
+// within a term are ORed. A leading '-' negates a term and a bare word is
+// shorthand for text:word. The query compiles to a WHERE clause whose values
+// are all bound as parameters, never spliced into the SQL.
+type Filter struct {
+	Source string
+	where  []string
+	args   []any
+}
+
+var filterKeys = []string{"priority", "status", "done", "text", "tag", "project", "created", "updated", "due"}
+
+// ParseFilter compiles a filter query. Relative times like 7d are measured
+// from now: created:<7d means created less than 7 days ago, and due:<3d means
+// due less than 3 days from now, overdue included.
+func ParseFilter(input string, now time.Time) (Filter, error) {
+	tokens, err := splitFilter(input)
+	if err != nil {
+		return Filter{}, err
+	}
+	f := Filter{Source: strings.Join(strings.Fields(input), " ")}
+	for _, token := range tokens {
+		negate := strings.HasPrefix(token, "-") && len(token) > 1
+		if negate {
+			token = token[1:]
+		}
+		key, value, ok := strings.Cut(token, ":")
+		if !ok {
+			key, value = "text", token
+		}
+		key = strings.ToLower(key)
+		value = unquote(value)
+		if value == "" {
+			return Filter{}, fmt.Errorf("filter %s: needs a value", key)
+		}
+		clause, args, err := compileTerm(key, value, now)
+		if err != nil {
+			return Filter{}, err
+		}
+		if negate {
+			// A NULL column fails the term, so its negation should pass.
+			clause = "NOT COALESCE((" + clause + "), FALSE)"
+		}
+		f.where = append(f.where, clause)
+		f.args = append(f.args, args...)
+	}
+	return f, nil
+}
+
+// Active reports whether the filter narrows anything.
+func (f Filter) Active() bool {
+	return len(f.where) > 0
+}
+
+// and narrows the filter by one more condition.
+func (f Filter) and(clause string, args ...any) Filter {
+	f.where = append(slices.Clip(f.where), clause)
+	f.args = append(slices.Clip(f.args), args...)
+	return f
+}
+
+// merge narrows the filter by every term of another.
+func (f Filter) merge(g Filter) Filter {
+	f.where = append(slices.Clip(f.where), g.where...)
+	f.args = append(slices.Clip(f.args), g.args...)
+	return f
+}
+
+// SQL returns the query selecting the ids of matching todos, with its
+// parameters. Trashed todos never match.
+func (f Filter) SQL() (string, []any) {
+	query := "SELECT id FROM todos WHERE deleted_at IS NULL"
+	for _, clause := range f.where {
+		query += " AND " + clause
+	}
+	return query, f.args
+}
+
+// splitFilter breaks a query into terms at whitespace outside double quotes.
+func splitFilter(input string) ([]string, error) {
+	var tokens []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.New("filter has an unterminated quote")
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// compileTerm turns one key:value term into a SQL condition and its
+// parameters.
+func compileTerm(key, value string, now time.Time) (string, []any, error) {
+	switch key {
+	case "priority":
+		values, err := listValues(value, func(v string) (any, error) {
+			p, err := parsePriority(v)
+			return string(p), err
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return "priority IN (" + placeholders(len(values)) + ")", values, nil
+	case "status":
+		values, err := listValues(value, func(v string) (any, error) {
+			s, err := ParseStatus(strings.ToLower(v))
+			return string(s), err
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return "status IN (" + placeholders(len(values)) + ")", values, nil
+	case "done":
+		switch strings.ToLower(value) {
+		case "yes", "true", "y":
+			return "completed = ?", []any{true}, nil
+		case "no", "false", "n":
+			return "completed = ?", []any{false}, nil
+		}
+		return "", nil, fmt.Errorf("filter done: %q should be yes or no", value)
+	case "text":
+		return `content LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(value) + "%"}, nil
+	case "tag":
+		values, err := listValues(value, func(v string) (any, error) {
+			return NormalizeTag(v)
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		return taggedWith(len(values)), values, nil
+	case "project":
+		if strings.EqualFold(value, "none") {
+			return "project_id IS NULL", nil, nil
+		}
+		values, err := listValues(value, func(v string) (any, error) { return v, nil })
+		if err != nil {
+			return "", nil, err
+		}
+		return "project_id IN (SELECT id FROM projects WHERE name IN (" + placeholders(len(values)) + "))", values, nil
+	case "created":
+		return compileTime("created_at", key, value, now, -1)
+	case "updated":
+		return compileTime("updated_at", key, value, now, -1)
+	case "due":
+		switch strings.ToLower(value) {
+		case "none":
+			return "due_at IS NULL", nil, nil
+		case "any":
+			return "due_at IS NOT NULL", nil, nil
+		}
+		return compileTime("due_at", key, value, now, 1)
+	}
+	return "", nil, fmt.Errorf("unknown filter %q (want %s)", key, strings.Join(filterKeys, ", "))
+}
+
+// compileTime compares a timestamp column against a date or a distance from
+// now. dir says which way distances run: -1 into the past, 1 into the
+// future. With a distance, < means nearer to now than that and > further;
+// with a date, < means before that day and > after it, and no operator means
+// on it.
+func compileTime(column, key, value string, now time.Time, dir int) (string, []any, error) {
+	op := ""
+	if strings.HasPrefix(value, "<") || strings.HasPrefix(value, ">") {
+		op, value = value[:1], value[1:]
+	}
+	col := "julianday(" + column + ")"
+	if m := relativeDuePattern.FindStringSubmatch(strings.ToLower(value)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		n *= dir
+		var at time.Time
+		switch m[2] {
+		case "d":
+			at = now.AddDate(0, 0, n)
+		case "w":
+			at = now.AddDate(0, 0, 7*n)
+		case "m":
+			at = now.AddDate(0, n, 0)
+		case "y":
+			at = now.AddDate(n, 0, 0)
+		}
+		if after := (op == ">") == (dir > 0); after {
+			return col + " > julianday(?)", []any{at}, nil
+		}
+		return col + " < julianday(?)", []any{at}, nil
+	}
+	day, err := ParseDue(value, now)
+	if err != nil {
+		return "", nil, fmt.Errorf("filter %s: can't understand %q (try 7d, 2w, today, or 2006-01-02)", key, value)
+	}
+	day = startOfDay(day)
+	next := day.AddDate(0, 0, 1)
+	switch op {
+	case "<":
+		return col + " < julianday(?)", []any{day}, nil
+	case ">":
+		return col + " >= julianday(?)", []any{next}, nil
+	}
+	return col + " >= julianday(?) AND " + col + " < julianday(?)", []any{day, next}, nil
+}
+
+// listValues parses a comma separated list of values with parse.
+func listValues(value string, parse func(string) (any, error)) ([]any, error) {
+	var values []any
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		parsed, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, parsed)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("filter needs at least one value in %q", value)
+	}
+	return values, nil
+}
+
+// taggedWith is the condition that a todo carries one of n tags, given as
+// parameters.
+func taggedWith(n int) string {
+	return "id IN (SELECT todo_tags.todo_id FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id WHERE tags.name IN (" + placeholders(n) + "))"
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// escapeLike makes LIKE's wildcards match themselves.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
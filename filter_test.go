@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func TestParseFilter(t *testing.T) {
+	now := time.Date(2026, time.June, 10, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time {
+		return time.Date(2026, time.June, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		input string
+		where []string
+		args  []any
+	}{
+		{"priority:p0,P1", []string{"priority IN (?, ?)"}, []any{"P0", "P1"}},
+		{"status:Doing", []string{"status IN (?)"}, []any{"doing"}},
+		{"done:no", []string{"completed = ?"}, []any{false}},
+		{"deploy", []string{`content LIKE ? ESCAPE '\'`}, []any{"%deploy%"}},
+		{`text:"50%_off now"`, []string{`content LIKE ? ESCAPE '\'`}, []any{`%50\%\_off now%`}},
+		{"tag:work,home", []string{taggedWith(2)}, []any{"work", "home"}},
+		{"-tag:work", []string{"NOT COALESCE((" + taggedWith(1) + "), FALSE)"}, []any{"work"}},
+		{"project:none", []string{"project_id IS NULL"}, nil},
+		{"created:<7d", []string{"julianday(created_at) > julianday(?)"}, []any{now.AddDate(0, 0, -7)}},
+		{"updated:>2w", []string{"julianday(updated_at) < julianday(?)"}, []any{now.AddDate(0, 0, -14)}},
+		{"due:<3d", []string{"julianday(due_at) < julianday(?)"}, []any{now.AddDate(0, 0, 3)}},
+		{"due:>1m", []string{"julianday(due_at) > julianday(?)"}, []any{now.AddDate(0, 1, 0)}},
+		{"due:any", []string{"due_at IS NOT NULL"}, nil},
+		{"due:2026-06-12", []string{"julianday(due_at) >= julianday(?) AND julianday(due_at) < julianday(?)"}, []any{day(12), day(13)}},
+		{"due:<tomorrow", []string{"julianday(due_at) < julianday(?)"}, []any{day(11)}},
+		{"created:>2026-06-01", []string{"julianday(created_at) >= julianday(?)"}, []any{day(2)}},
+		{
+			"priority:P0   done:no  deploy",
+			[]string{"priority IN (?)", "completed = ?", `content LIKE ? ESCAPE '\'`},
+			[]any{"P0", false, "%deploy%"},
+		},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.input, now)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.input, err)
+			continue
+		}
+		if !slices.Equal(f.where, tt.where) {
+			t.Errorf("ParseFilter(%q) where = %q, want %q", tt.input, f.where, tt.where)
+		}
+		if !slices.EqualFunc(f.args, tt.args, argEqual) {
+			t.Errorf("ParseFilter(%q) args = %v, want %v", tt.input, f.args, tt.args)
+		}
+	}
+
+	f, err := ParseFilter("  priority:P0   deploy ", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Source != "priority:P0 deploy" {
+		t.Errorf("Source = %q, want the query with its spacing collapsed", f.Source)
+	}
+	query, args := f.SQL()
+	want := `SELECT id FROM todos WHERE deleted_at IS NULL AND priority IN (?) AND content LIKE ? ESCAPE '\'`
+	if query != want || len(args) != 2 {
+		t.Errorf("SQL() = %q with %d args, want %q with 2", query, len(args), want)
+	}
+	if f, err := ParseFilter(" ", now); err != nil || f.Active() {
+		t.Errorf("ParseFilter of a blank query = %+v, %v; want an inactive filter", f, err)
+	}
+
+	for _, input := range []string{
+		"color:red",
+		"priority:",
+		"priority:P9",
+		"status:later",
+		"done:maybe",
+		"tag:,",
+		`text:"unterminated`,
+		"created:<soon",
+		"due:>3h",
+	} {
+		if _, err := ParseFilter(input, now); err == nil {
+			t.Errorf("ParseFilter(%q) should fail", input)
+		}
+	}
+}
+
+// argEqual compares filter parameters, matching times by instant.
+func argEqual(a, b any) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
+
+// TestFilterSQL runs filters against a database, checking that the compiled
+// SQL picks out the intended todos.
+func TestFilterSQL(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	project, err := d.CreateProject(ctx, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(content string, priority Priority, age time.Duration, due time.Duration) orm.Todo {
+		t.Helper()
+		arg := orm.CreateTodoParams{
+			Content:   content,
+			Priority:  string(priority),
+			CreatedAt: now.Add(-age),
+			UpdatedAt: now.Add(-age),
+		}
+		if due != 0 {
+			arg.DueAt = sql.NullTime{Time: now.Add(due), Valid: true}
+		}
+		todo, err := d.Queries.CreateTodo(ctx, arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return todo
+	}
+	const day = 24 * time.Hour
+	report := create("write report", P0, 2*day, day)
+	rollout := create("deploy 50% rollout", P1, 10*day, 0)
+	milk := create("buy milk", P2, day, -day)
+	errs := create("500 errors", P2, 30*day, 10*day)
+	trashed := create("trashed deploy", P0, day, 0)
+
+	err = d.Queries.MoveTodoToProject(ctx, orm.MoveTodoToProjectParams{ProjectID: nullID(project.ID), UpdatedAt: now, ID: report.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetTags(ctx, report.ID, []string{"work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetTags(ctx, errs.ID, []string{"bug"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.SetStatus(ctx, rollout.ID, StatusDoing); err != nil {
+		t.Fatal(err)
+	}
+	err = d.Queries.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{CompletedAt: sql.NullTime{Time: now, Valid: true}, UpdatedAt: now, ID: milk.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Trash(ctx, trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []orm.Todo
+	}{
+		{"priority:P0,P1", []orm.Todo{report, rollout}},
+		{"deploy", []orm.Todo{rollout}},
+		{`text:"50%"`, []orm.Todo{rollout}},
+		{"done:yes", []orm.Todo{milk}},
+		{"priority:P2 done:no", []orm.Todo{errs}},
+		{"status:doing", []orm.Todo{rollout}},
+		{"created:<7d", []orm.Todo{report, milk}},
+		{"created:>7d", []orm.Todo{rollout, errs}},
+		{"due:<3d", []orm.Todo{report, milk}},
+		{"-due:<3d", []orm.Todo{rollout, errs}},
+		{"due:none", []orm.Todo{rollout}},
+		{"due:" + now.AddDate(0, 0, 1).Format("2006-01-02"), []orm.Todo{report}},
+		{"tag:work,bug", []orm.Todo{report, errs}},
+		{"-tag:work", []orm.Todo{rollout, milk, errs}},
+		{"project:home", []orm.Todo{report}},
+		{"project:none", []orm.Todo{rollout, milk, errs}},
+		{"-project:home -tag:bug", []orm.Todo{rollout, milk}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.query, now)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.query, err)
+			continue
+		}
+		ids, err := filterIDs(ctx, d, f)
+		if err != nil {
+			t.Errorf("filterIDs(%q): %v", tt.query, err)
+			continue
+		}
+		want := map[int]bool{}
+		for _, todo := range tt.want {
+			want[todo.ID] = true
+		}
+		if !maps.Equal(ids, want) {
+			t.Errorf("filterIDs(%q) = %v, want %v", tt.query, ids, want)
+		}
+	}
+}
+
+// filterIDs runs a filter against the todos table, returning the ids that
+// match.
+func filterIDs(ctx context.Context, d *Database, f Filter) (map[int]bool, error) {
+	query, args := f.SQL()
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
@@ -5,14 +5,83 @@
 package orm
 
 import (
+	"database/sql"
 	"time"
 )
 
+type BoardColumn struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	WipLimit int    `json:"wip_limit"`
+}
+
+type Project struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SavedView struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TabSort struct {
+	Tab        string `json:"tab"`
+	Mode       string `json:"mode"`
+	Descending bool   `json:"descending"`
+}
+
+type Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type TimeEntry struct {
+	ID        int          `json:"id"`
+	TodoID    int          `json:"todo_id"`
+	StartedAt time.Time    `json:"started_at"`
+	StoppedAt sql.NullTime `json:"stopped_at"`
+}
+
 type Todo struct {
+	ID                 int           `json:"id"`
+	Content            string        `json:"content"`
+	Priority           string        `json:"priority"`
+	Completed          bool          `json:"completed"`
+	CreatedAt          time.Time     `json:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+	DueAt              sql.NullTime  `json:"due_at"`
+	ProjectID          sql.NullInt64 `json:"project_id"`
+	ParentID           sql.NullInt64 `json:"parent_id"`
+	Notes              string        `json:"notes"`
+	Recurrence         string        `json:"recurrence"`
+	Position           int           `json:"position"`
+	DeletedAt          sql.NullTime  `json:"deleted_at"`
+	CompletedAt        sql.NullTime  `json:"completed_at"`
+	Status             string        `json:"status"`
+	ColumnID           sql.NullInt64 `json:"column_id"`
+	RecurrenceSourceID sql.NullInt64 `json:"recurrence_source_id"`
+}
+
+type TodoDependency struct {
+	TodoID    int `json:"todo_id"`
+	BlockerID int `json:"blocker_id"`
+}
+
+type TodoEvent struct {
 	ID        int       `json:"id"`
-	Content   string    `json:"content"`
-	Priority  string    `json:"priority"`
-	Completed bool      `json:"completed"`
+	TodoID    int       `json:"todo_id"`
+	Kind      string    `json:"kind"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TodoTag struct {
+	TodoID int `json:"todo_id"`
+	TagID  int `json:"tag_id"`
 }
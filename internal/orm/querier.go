@@ -6,18 +6,85 @@ package orm
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AddDependency(ctx context.Context, arg AddDependencyParams) error
+	AttachTag(ctx context.Context, arg AttachTagParams) error
+	ClearTodoDueAt(ctx context.Context, arg ClearTodoDueAtParams) error
+	CompleteTodoDescendants(ctx context.Context, arg CompleteTodoDescendantsParams) error
+	CountActiveProjectTodos(ctx context.Context, projectID sql.NullInt64) (int64, error)
 	CountActiveTodos(ctx context.Context) (int64, error)
 	CountCompletedTodos(ctx context.Context) (int64, error)
+	CountDependencyPath(ctx context.Context, arg CountDependencyPathParams) (int64, error)
+	CountNextOccurrences(ctx context.Context, recurrenceSourceID sql.NullInt64) (int64, error)
+	CountOpenBlockers(ctx context.Context, todoID int) (int64, error)
+	CountTrashedTodos(ctx context.Context) (int64, error)
+	CreateColumn(ctx context.Context, name string) (BoardColumn, error)
+	CreateProject(ctx context.Context, arg CreateProjectParams) (Project, error)
 	CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error)
+	CreateView(ctx context.Context, arg CreateViewParams) (SavedView, error)
+	DeleteColumn(ctx context.Context, id int) error
+	DeleteProject(ctx context.Context, id int) error
+	DeleteTabSort(ctx context.Context, tab string) error
 	DeleteTodo(ctx context.Context, id int) error
+	DeleteUnusedTags(ctx context.Context) error
+	DeleteView(ctx context.Context, id int) error
+	DetachFromTrashedParent(ctx context.Context, id int) error
+	DetachTag(ctx context.Context, arg DetachTagParams) error
+	EmptyTrash(ctx context.Context) (int64, error)
 	GetActiveTodos(ctx context.Context) ([]Todo, error)
+	GetBlockers(ctx context.Context, todoID int) ([]int, error)
+	GetColumnByName(ctx context.Context, name string) (BoardColumn, error)
 	GetCompletedTodos(ctx context.Context) ([]Todo, error)
+	GetDependents(ctx context.Context, blockerID int) ([]int, error)
+	GetOpenBlockers(ctx context.Context, ids string) ([]GetOpenBlockersRow, error)
+	GetProjectByName(ctx context.Context, name string) (Project, error)
+	GetRunningTimeEntry(ctx context.Context) (TimeEntry, error)
+	GetSubtaskProgress(ctx context.Context, ids string) ([]GetSubtaskProgressRow, error)
+	GetTagsForTodos(ctx context.Context, ids string) ([]GetTagsForTodosRow, error)
+	GetTimeEntriesSince(ctx context.Context, since time.Time) ([]TimeEntry, error)
+	GetTodo(ctx context.Context, id int) (Todo, error)
+	GetTodoDescendants(ctx context.Context, parentID sql.NullInt64) ([]Todo, error)
+	GetTodoEvents(ctx context.Context, todoID int) ([]TodoEvent, error)
+	GetTodoTags(ctx context.Context, todoID int) ([]Tag, error)
+	GetTrackedSeconds(ctx context.Context, ids string) ([]GetTrackedSecondsRow, error)
+	GetTrashedBefore(ctx context.Context, cutoff time.Time) ([]int, error)
+	GetTrashedTodos(ctx context.Context) ([]Todo, error)
+	GetViewByName(ctx context.Context, name string) (SavedView, error)
+	InsertTodoEvent(ctx context.Context, arg InsertTodoEventParams) error
+	ListColumns(ctx context.Context) ([]BoardColumn, error)
+	ListProjects(ctx context.Context) ([]Project, error)
+	ListTabSorts(ctx context.Context) ([]TabSort, error)
+	ListViews(ctx context.Context) ([]SavedView, error)
+	MoveTodoToProject(ctx context.Context, arg MoveTodoToProjectParams) error
+	PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	RemoveDependency(ctx context.Context, arg RemoveDependencyParams) error
+	RenameColumn(ctx context.Context, arg RenameColumnParams) error
+	RenameProject(ctx context.Context, arg RenameProjectParams) error
+	RenameView(ctx context.Context, arg RenameViewParams) error
+	RestoreTodo(ctx context.Context, id int) error
+	SearchTodos(ctx context.Context, query string) ([]SearchTodosRow, error)
+	SetColumnWipLimit(ctx context.Context, arg SetColumnWipLimitParams) error
+	SetTabSort(ctx context.Context, arg SetTabSortParams) error
+	SetTodoColumn(ctx context.Context, arg SetTodoColumnParams) error
+	SetTodoDueAt(ctx context.Context, arg SetTodoDueAtParams) error
+	SetTodoParent(ctx context.Context, arg SetTodoParentParams) error
+	SetTodoPosition(ctx context.Context, arg SetTodoPositionParams) error
+	SetTodoRecurrence(ctx context.Context, arg SetTodoRecurrenceParams) error
+	SetTodoRecurrenceSource(ctx context.Context, arg SetTodoRecurrenceSourceParams) error
+	SetTodoStatus(ctx context.Context, arg SetTodoStatusParams) error
+	StartTimeEntry(ctx context.Context, arg StartTimeEntryParams) (TimeEntry, error)
+	StopRunningTimeEntries(ctx context.Context, stoppedAt sql.NullTime) error
+	StopTodoTimeEntries(ctx context.Context, arg StopTodoTimeEntriesParams) error
 	ToggleTodoCompleted(ctx context.Context, arg ToggleTodoCompletedParams) error
+	TrashTodo(ctx context.Context, arg TrashTodoParams) error
 	UpdateTodoContent(ctx context.Context, arg UpdateTodoContentParams) error
+	UpdateTodoNotes(ctx context.Context, arg UpdateTodoNotesParams) error
 	UpdateTodoPriority(ctx context.Context, arg UpdateTodoPriorityParams) error
+	UpsertTag(ctx context.Context, name string) (Tag, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,36 @@
+package orm
+
+// This file is written by hand, not by sqlc. It backs the queries that are
+// built at run time, such as keyset paging, which sqlc can't generate.
+
+import "database/sql"
+
+// TodoColumns lists the todos columns in the order of the Todo fields in
+// models.go. When a column is added to the schema, add it here and to
+// ScanTodo as well; TestTodoColumns checks both against the table.
+const TodoColumns = "id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id"
+
+// ScanTodo reads a row selected with TodoColumns.
+func ScanTodo(rows *sql.Rows) (Todo, error) {
+	var t Todo
+	err := rows.Scan(
+		&t.ID,
+		&t.Content,
+		&t.Priority,
+		&t.Completed,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.DueAt,
+		&t.ProjectID,
+		&t.ParentID,
+		&t.Notes,
+		&t.Recurrence,
+		&t.Position,
+		&t.DeletedAt,
+		&t.CompletedAt,
+		&t.Status,
+		&t.ColumnID,
+		&t.RecurrenceSourceID,
+	)
+	return t, err
+}
@@ -7,11 +7,50 @@ package orm
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
+const clearTodoDueAt = `-- name: ClearTodoDueAt :exec
+UPDATE todos
+SET due_at = NULL, updated_at = ?
+WHERE id = ?
+`
+
+type ClearTodoDueAtParams struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        int       `json:"id"`
+}
+
+func (q *Queries) ClearTodoDueAt(ctx context.Context, arg ClearTodoDueAtParams) error {
+	_, err := q.db.ExecContext(ctx, clearTodoDueAt, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const completeTodoDescendants = `-- name: CompleteTodoDescendants :exec
+WITH RECURSIVE descendants (id) AS (
+    SELECT child.id FROM todos AS child WHERE child.parent_id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN descendants ON child.parent_id = descendants.id
+)
+UPDATE todos
+SET completed = TRUE, status = 'done', completed_at = ?, updated_at = ?
+WHERE completed = FALSE AND deleted_at IS NULL AND id IN (SELECT id FROM descendants)
+`
+
+type CompleteTodoDescendantsParams struct {
+	ParentID    sql.NullInt64 `json:"parent_id"`
+	CompletedAt sql.NullTime  `json:"completed_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+func (q *Queries) CompleteTodoDescendants(ctx context.Context, arg CompleteTodoDescendantsParams) error {
+	_, err := q.db.ExecContext(ctx, completeTodoDescendants, arg.ParentID, arg.CompletedAt, arg.UpdatedAt)
+	return err
+}
+
 const countActiveTodos = `-- name: CountActiveTodos :one
-SELECT COUNT(*) FROM todos WHERE completed = FALSE
+SELECT COUNT(*) FROM todos WHERE completed = FALSE AND deleted_at IS NULL
 `
 
 func (q *Queries) CountActiveTodos(ctx context.Context) (int64, error) {
@@ -22,7 +61,7 @@ func (q *Queries) CountActiveTodos(ctx context.Context) (int64, error) {
 }
 
 const countCompletedTodos = `-- name: CountCompletedTodos :one
-SELECT COUNT(*) FROM todos WHERE completed = TRUE
+SELECT COUNT(*) FROM todos WHERE completed = TRUE AND deleted_at IS NULL
 `
 
 func (q *Queries) CountCompletedTodos(ctx context.Context) (int64, error) {
@@ -32,17 +71,44 @@ func (q *Queries) CountCompletedTodos(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const countNextOccurrences = `-- name: CountNextOccurrences :one
+SELECT COUNT(*)
+FROM todos
+WHERE recurrence_source_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) CountNextOccurrences(ctx context.Context, recurrenceSourceID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNextOccurrences, recurrenceSourceID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTrashedTodos = `-- name: CountTrashedTodos :one
+SELECT COUNT(*) FROM todos WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountTrashedTodos(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTrashedTodos)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTodo = `-- name: CreateTodo :one
-INSERT INTO todos (content, priority, created_at, updated_at)
-VALUES (?, ?, ?, ?)
-RETURNING id, content, priority, completed, created_at, updated_at
+INSERT INTO todos (content, priority, created_at, updated_at, due_at, project_id, parent_id, position)
+VALUES (?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MIN(position), 1) - 1 FROM todos))
+RETURNING id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
 `
 
 type CreateTodoParams struct {
-	Content   string    `json:"content"`
-	Priority  string    `json:"priority"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Content   string        `json:"content"`
+	Priority  string        `json:"priority"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	DueAt     sql.NullTime  `json:"due_at"`
+	ProjectID sql.NullInt64 `json:"project_id"`
+	ParentID  sql.NullInt64 `json:"parent_id"`
 }
 
 func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, error) {
@@ -51,6 +117,9 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		arg.Priority,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.DueAt,
+		arg.ProjectID,
+		arg.ParentID,
 	)
 	var i Todo
 	err := row.Scan(
@@ -60,6 +129,17 @@ func (q *Queries) CreateTodo(ctx context.Context, arg CreateTodoParams) (Todo, e
 		&i.Completed,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DueAt,
+		&i.ProjectID,
+		&i.ParentID,
+		&i.Notes,
+		&i.Recurrence,
+		&i.Position,
+		&i.DeletedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.ColumnID,
+		&i.RecurrenceSourceID,
 	)
 	return i, err
 }
@@ -73,10 +153,33 @@ func (q *Queries) DeleteTodo(ctx context.Context, id int) error {
 	return err
 }
 
+const detachFromTrashedParent = `-- name: DetachFromTrashedParent :exec
+UPDATE todos
+SET parent_id = NULL
+WHERE id = ? AND parent_id IN (SELECT parent.id FROM todos AS parent WHERE parent.deleted_at IS NOT NULL)
+`
+
+func (q *Queries) DetachFromTrashedParent(ctx context.Context, id int) error {
+	_, err := q.db.ExecContext(ctx, detachFromTrashedParent, id)
+	return err
+}
+
+const emptyTrash = `-- name: EmptyTrash :execrows
+DELETE FROM todos WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) EmptyTrash(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, emptyTrash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getActiveTodos = `-- name: GetActiveTodos :many
-SELECT id, content, priority, completed, created_at, updated_at 
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id 
 FROM todos 
-WHERE completed = FALSE 
+WHERE completed = FALSE AND deleted_at IS NULL
 ORDER BY priority ASC, created_at DESC
 `
 
@@ -96,6 +199,17 @@ func (q *Queries) GetActiveTodos(ctx context.Context) ([]Todo, error) {
 			&i.Completed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DueAt,
+			&i.ProjectID,
+			&i.ParentID,
+			&i.Notes,
+			&i.Recurrence,
+			&i.Position,
+			&i.DeletedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.ColumnID,
+			&i.RecurrenceSourceID,
 		); err != nil {
 			return nil, err
 		}
@@ -111,10 +225,10 @@ func (q *Queries) GetActiveTodos(ctx context.Context) ([]Todo, error) {
 }
 
 const getCompletedTodos = `-- name: GetCompletedTodos :many
-SELECT id, content, priority, completed, created_at, updated_at 
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id 
 FROM todos 
-WHERE completed = TRUE 
-ORDER BY updated_at DESC
+WHERE completed = TRUE AND deleted_at IS NULL
+ORDER BY completed_at DESC, id DESC
 `
 
 func (q *Queries) GetCompletedTodos(ctx context.Context) ([]Todo, error) {
@@ -133,6 +247,17 @@ func (q *Queries) GetCompletedTodos(ctx context.Context) ([]Todo, error) {
 			&i.Completed,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DueAt,
+			&i.ProjectID,
+			&i.ParentID,
+			&i.Notes,
+			&i.Recurrence,
+			&i.Position,
+			&i.DeletedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.ColumnID,
+			&i.RecurrenceSourceID,
 		); err != nil {
 			return nil, err
 		}
@@ -147,19 +272,405 @@ func (q *Queries) GetCompletedTodos(ctx context.Context) ([]Todo, error) {
 	return items, nil
 }
 
-const toggleTodoCompleted = `-- name: ToggleTodoCompleted :exec
-UPDATE todos 
-SET completed = NOT completed, updated_at = ? 
+const getSubtaskProgress = `-- name: GetSubtaskProgress :many
+SELECT parent_id, COUNT(*) AS total, COUNT(CASE WHEN completed THEN 1 END) AS done
+FROM todos
+WHERE parent_id IN (SELECT value FROM json_each(?)) AND deleted_at IS NULL
+GROUP BY parent_id
+`
+
+type GetSubtaskProgressRow struct {
+	ParentID sql.NullInt64 `json:"parent_id"`
+	Total    int64         `json:"total"`
+	Done     int64         `json:"done"`
+}
+
+func (q *Queries) GetSubtaskProgress(ctx context.Context, ids string) ([]GetSubtaskProgressRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSubtaskProgress, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSubtaskProgressRow{}
+	for rows.Next() {
+		var i GetSubtaskProgressRow
+		if err := rows.Scan(
+			&i.ParentID,
+			&i.Total,
+			&i.Done,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTodo = `-- name: GetTodo :one
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
 WHERE id = ?
 `
 
-type ToggleTodoCompletedParams struct {
+func (q *Queries) GetTodo(ctx context.Context, id int) (Todo, error) {
+	row := q.db.QueryRowContext(ctx, getTodo, id)
+	var i Todo
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.Priority,
+		&i.Completed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DueAt,
+		&i.ProjectID,
+		&i.ParentID,
+		&i.Notes,
+		&i.Recurrence,
+		&i.Position,
+		&i.DeletedAt,
+		&i.CompletedAt,
+		&i.Status,
+		&i.ColumnID,
+		&i.RecurrenceSourceID,
+	)
+	return i, err
+}
+
+const getTodoDescendants = `-- name: GetTodoDescendants :many
+WITH RECURSIVE descendants (id) AS (
+    SELECT child.id FROM todos AS child WHERE child.parent_id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN descendants ON child.parent_id = descendants.id
+)
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
+WHERE deleted_at IS NULL AND id IN (SELECT id FROM descendants)
+ORDER BY priority ASC, created_at DESC
+`
+
+func (q *Queries) GetTodoDescendants(ctx context.Context, parentID sql.NullInt64) ([]Todo, error) {
+	rows, err := q.db.QueryContext(ctx, getTodoDescendants, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.Priority,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DueAt,
+			&i.ProjectID,
+			&i.ParentID,
+			&i.Notes,
+			&i.Recurrence,
+			&i.Position,
+			&i.DeletedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.ColumnID,
+			&i.RecurrenceSourceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashedBefore = `-- name: GetTrashedBefore :many
+SELECT id FROM todos WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?) ORDER BY id ASC
+`
+
+func (q *Queries) GetTrashedBefore(ctx context.Context, cutoff time.Time) ([]int, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedBefore, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashedTodos = `-- name: GetTrashedTodos :many
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+`
+
+func (q *Queries) GetTrashedTodos(ctx context.Context) ([]Todo, error) {
+	rows, err := q.db.QueryContext(ctx, getTrashedTodos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Todo{}
+	for rows.Next() {
+		var i Todo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.Priority,
+			&i.Completed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DueAt,
+			&i.ProjectID,
+			&i.ParentID,
+			&i.Notes,
+			&i.Recurrence,
+			&i.Position,
+			&i.DeletedAt,
+			&i.CompletedAt,
+			&i.Status,
+			&i.ColumnID,
+			&i.RecurrenceSourceID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveTodoToProject = `-- name: MoveTodoToProject :exec
+UPDATE todos
+SET project_id = ?, updated_at = ?
+WHERE id = ?
+`
+
+type MoveTodoToProjectParams struct {
+	ProjectID sql.NullInt64 `json:"project_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	ID        int           `json:"id"`
+}
+
+func (q *Queries) MoveTodoToProject(ctx context.Context, arg MoveTodoToProjectParams) error {
+	_, err := q.db.ExecContext(ctx, moveTodoToProject, arg.ProjectID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const purgeTrashedBefore = `-- name: PurgeTrashedBefore :execrows
+DELETE FROM todos WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?)
+`
+
+func (q *Queries) PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTrashedBefore, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreTodo = `-- name: RestoreTodo :exec
+WITH RECURSIVE subtree (id) AS (
+    SELECT todos.id FROM todos WHERE todos.id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN subtree ON child.parent_id = subtree.id
+)
+UPDATE todos
+SET deleted_at = NULL
+WHERE id IN (SELECT id FROM subtree)
+`
+
+func (q *Queries) RestoreTodo(ctx context.Context, id int) error {
+	_, err := q.db.ExecContext(ctx, restoreTodo, id)
+	return err
+}
+
+const setTodoColumn = `-- name: SetTodoColumn :exec
+UPDATE todos
+SET column_id = ?, updated_at = ?
+WHERE id = ?
+`
+
+type SetTodoColumnParams struct {
+	ColumnID  sql.NullInt64 `json:"column_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	ID        int           `json:"id"`
+}
+
+func (q *Queries) SetTodoColumn(ctx context.Context, arg SetTodoColumnParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoColumn, arg.ColumnID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setTodoDueAt = `-- name: SetTodoDueAt :exec
+UPDATE todos
+SET due_at = ?, updated_at = ?
+WHERE id = ?
+`
+
+type SetTodoDueAtParams struct {
+	DueAt     sql.NullTime `json:"due_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	ID        int          `json:"id"`
+}
+
+func (q *Queries) SetTodoDueAt(ctx context.Context, arg SetTodoDueAtParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoDueAt, arg.DueAt, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setTodoParent = `-- name: SetTodoParent :exec
+UPDATE todos
+SET parent_id = ?, updated_at = ?
+WHERE id = ?
+`
+
+type SetTodoParentParams struct {
+	ParentID  sql.NullInt64 `json:"parent_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	ID        int           `json:"id"`
+}
+
+func (q *Queries) SetTodoParent(ctx context.Context, arg SetTodoParentParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoParent, arg.ParentID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setTodoPosition = `-- name: SetTodoPosition :exec
+UPDATE todos
+SET position = ?
+WHERE id = ?
+`
+
+type SetTodoPositionParams struct {
+	Position int `json:"position"`
+	ID       int `json:"id"`
+}
+
+func (q *Queries) SetTodoPosition(ctx context.Context, arg SetTodoPositionParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoPosition, arg.Position, arg.ID)
+	return err
+}
+
+const setTodoRecurrence = `-- name: SetTodoRecurrence :exec
+UPDATE todos
+SET recurrence = ?, updated_at = ?
+WHERE id = ?
+`
+
+type SetTodoRecurrenceParams struct {
+	Recurrence string    `json:"recurrence"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ID         int       `json:"id"`
+}
+
+func (q *Queries) SetTodoRecurrence(ctx context.Context, arg SetTodoRecurrenceParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoRecurrence, arg.Recurrence, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setTodoRecurrenceSource = `-- name: SetTodoRecurrenceSource :exec
+UPDATE todos
+SET recurrence_source_id = ?
+WHERE id = ?
+`
+
+type SetTodoRecurrenceSourceParams struct {
+	RecurrenceSourceID sql.NullInt64 `json:"recurrence_source_id"`
+	ID                 int           `json:"id"`
+}
+
+func (q *Queries) SetTodoRecurrenceSource(ctx context.Context, arg SetTodoRecurrenceSourceParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoRecurrenceSource, arg.RecurrenceSourceID, arg.ID)
+	return err
+}
+
+const setTodoStatus = `-- name: SetTodoStatus :exec
+UPDATE todos
+SET status = ?, updated_at = ?
+WHERE id = ?
+`
+
+type SetTodoStatusParams struct {
+	Status    string    `json:"status"`
 	UpdatedAt time.Time `json:"updated_at"`
 	ID        int       `json:"id"`
 }
 
+func (q *Queries) SetTodoStatus(ctx context.Context, arg SetTodoStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setTodoStatus, arg.Status, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const toggleTodoCompleted = `-- name: ToggleTodoCompleted :exec
+UPDATE todos
+SET completed = NOT completed,
+    status = CASE WHEN completed THEN 'todo' ELSE 'done' END,
+    completed_at = CASE WHEN completed THEN NULL ELSE ? END,
+    updated_at = ?
+WHERE id = ?
+`
+
+type ToggleTodoCompletedParams struct {
+	CompletedAt sql.NullTime `json:"completed_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	ID          int          `json:"id"`
+}
+
 func (q *Queries) ToggleTodoCompleted(ctx context.Context, arg ToggleTodoCompletedParams) error {
-	_, err := q.db.ExecContext(ctx, toggleTodoCompleted, arg.UpdatedAt, arg.ID)
+	_, err := q.db.ExecContext(ctx, toggleTodoCompleted, arg.CompletedAt, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const trashTodo = `-- name: TrashTodo :exec
+WITH RECURSIVE subtree (id) AS (
+    SELECT todos.id FROM todos WHERE todos.id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN subtree ON child.parent_id = subtree.id
+)
+UPDATE todos
+SET deleted_at = ?
+WHERE deleted_at IS NULL AND id IN (SELECT id FROM subtree)
+`
+
+type TrashTodoParams struct {
+	ID        int          `json:"id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) TrashTodo(ctx context.Context, arg TrashTodoParams) error {
+	_, err := q.db.ExecContext(ctx, trashTodo, arg.ID, arg.DeletedAt)
 	return err
 }
 
@@ -180,6 +691,23 @@ func (q *Queries) UpdateTodoContent(ctx context.Context, arg UpdateTodoContentPa
 	return err
 }
 
+const updateTodoNotes = `-- name: UpdateTodoNotes :exec
+UPDATE todos
+SET notes = ?, updated_at = ?
+WHERE id = ?
+`
+
+type UpdateTodoNotesParams struct {
+	Notes     string    `json:"notes"`
+	UpdatedAt time.Time `json:"updated_at"`
+	ID        int       `json:"id"`
+}
+
+func (q *Queries) UpdateTodoNotes(ctx context.Context, arg UpdateTodoNotesParams) error {
+	_, err := q.db.ExecContext(ctx, updateTodoNotes, arg.Notes, arg.UpdatedAt, arg.ID)
+	return err
+}
+
 const updateTodoPriority = `-- name: UpdateTodoPriority :exec
 UPDATE todos 
 SET priority = ?, updated_at = ? 
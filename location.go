@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DataDir returns the directory godoit keeps its data in, honouring
+// $XDG_DATA_HOME and falling back to ~/.local/share.
+func DataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "godoit"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "godoit"), nil
+}
+
+// DefaultProfile is the profile backed by the original todos.db file.
+const DefaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateProfileName rejects names that could escape the profiles directory.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// ProfilePath returns the database file backing a named profile. The default
+// profile keeps living at todos.db so existing lists carry over untouched.
+func ProfilePath(name string) (string, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return filepath.Join(dir, "todos.db"), nil
+	}
+	return filepath.Join(dir, "profiles", name+".db"), nil
+}
+
+// ListProfiles returns the default profile followed by every other profile
+// that has a database file, sorted by name.
+func ListProfiles() ([]string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "profiles", "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	profiles := []string{DefaultProfile}
+	for _, m := range matches {
+		name := strings.TrimSuffix(filepath.Base(m), ".db")
+		if name != DefaultProfile && ValidateProfileName(name) == nil {
+			profiles = append(profiles, name)
+		}
+	}
+	sort.Strings(profiles[1:])
+	return profiles, nil
+}
+
+// RepoDirName is the directory that marks a repository-scoped todo list,
+// found by walking up from the working directory the way git finds .git.
+const RepoDirName = ".godoit"
+
+// RepoDBPath returns the database file inside a repository's .godoit directory.
+func RepoDBPath(root string) string {
+	return filepath.Join(root, RepoDirName, "todos.db")
+}
+
+// FindRepoRoot walks up from start looking for a directory that contains
+// .godoit/todos.db and returns it, or "" if no ancestor has one.
+func FindRepoRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if info, err := os.Stat(RepoDBPath(dir)); err == nil && info.Mode().IsRegular() {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// DBLocation records which database file was chosen and how it was found.
+type DBLocation struct {
+	Path string
+	// Profile is set when the file belongs to a named profile.
+	Profile string
+	// RepoRoot is set when the file was discovered in a .godoit directory.
+	RepoRoot string
+}
+
+// Scope describes the location for display, e.g. "repo: godoit".
+func (l DBLocation) Scope() string {
+	switch {
+	case l.RepoRoot != "":
+		return "repo: " + filepath.Base(l.RepoRoot)
+	case l.Profile != "":
+		return "global"
+	default:
+		return "file: " + filepath.Base(l.Path)
+	}
+}
+
+// ResolveLocation picks the database file to open. An explicit path (from
+// --db) wins, then an explicit --profile, then $GODOIT_DB, then the nearest
+// repository's .godoit/todos.db, and finally the default profile.
+func ResolveLocation(explicit, profile string) (DBLocation, error) {
+	if explicit != "" && profile != "" {
+		return DBLocation{}, errors.New("--db and --profile cannot be used together")
+	}
+	if explicit != "" {
+		return DBLocation{Path: explicit}, nil
+	}
+	if profile != "" {
+		path, err := ProfilePath(profile)
+		return DBLocation{Path: path, Profile: profile}, err
+	}
+	if env := os.Getenv("GODOIT_DB"); env != "" {
+		return DBLocation{Path: env}, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return DBLocation{}, err
+	}
+	root, err := FindRepoRoot(cwd)
+	if err != nil {
+		return DBLocation{}, err
+	}
+	if root != "" {
+		return DBLocation{Path: RepoDBPath(root), RepoRoot: root}, nil
+	}
+	path, err := ProfilePath(DefaultProfile)
+	return DBLocation{Path: path, Profile: DefaultProfile}, err
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,16 +10,40 @@ import (
 )
 
 func main() {
-	db, err := NewDatabase()
+	dbFlag := flag.String("db", "", "path to the todo database file")
+	profileFlag := flag.String("profile", "", "named profile to open (default \"default\")")
+	trashFlag := flag.Int("trash-days", -1, fmt.Sprintf("days to keep deleted todos in the trash, 0 for forever (default %d)", DefaultTrashDays))
+	flag.Usage = func() { usage(os.Stderr) }
+	flag.Parse()
+	days, err := ResolveTrashDays(*trashFlag)
+	if err != nil {
+		log.Fatalf("Failed to read trash retention: %v", err)
+	}
+	location, err := ResolveLocation(*dbFlag, *profileFlag)
+	if err != nil {
+		log.Fatalf("Failed to locate database: %v", err)
+	}
+	if flag.NArg() > 0 {
+		if err := runCommand(location.Path, days, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "godoit: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	db, err := NewDatabase(location.Path, days)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer db.Close()
-	s := InitialState(db)
+	s := InitialState(db, location)
 	p := tea.NewProgram(s, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	m, err := p.Run()
+	// the TUI may have switched profiles, so close whichever database it ended on
+	if final, ok := m.(State); ok {
+		db = final.database
+	}
+	db.Close()
+	if err != nil {
 		fmt.Printf("Error running program: %v", err)
 		os.Exit(1)
 	}
 }
-
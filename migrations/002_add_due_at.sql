@@ -0,0 +1,10 @@
+-- +goose Up
+-- Optional deadline for a todo
+ALTER TABLE todos ADD COLUMN due_at DATETIME;
+
+-- Index for sorting by due date
+CREATE INDEX IF NOT EXISTS idx_todos_due_at ON todos(due_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_due_at;
+ALTER TABLE todos DROP COLUMN due_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Labels that can be attached to any number of todos
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+-- Join table between todos and tags
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+-- Index for looking up todos by tag
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag_id ON todo_tags(tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todo_tags_tag_id;
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
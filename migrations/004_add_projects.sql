@@ -0,0 +1,18 @@
+-- +goose Up
+-- Named lists that todos can belong to
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Todos outside any project keep a NULL project_id
+ALTER TABLE todos ADD COLUMN project_id INTEGER REFERENCES projects(id) ON DELETE SET NULL;
+
+-- Index for listing a project's todos
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos(project_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_project_id;
+ALTER TABLE todos DROP COLUMN project_id;
+DROP TABLE IF EXISTS projects;
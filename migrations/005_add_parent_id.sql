@@ -0,0 +1,10 @@
+-- +goose Up
+-- Subtasks point at their parent; deleting a parent deletes its subtasks
+ALTER TABLE todos ADD COLUMN parent_id INTEGER REFERENCES todos(id) ON DELETE CASCADE;
+
+-- Index for walking a todo's children
+CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos(parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_parent_id;
+ALTER TABLE todos DROP COLUMN parent_id;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Long-form notes shown in the detail pane
+ALTER TABLE todos ADD COLUMN notes TEXT DEFAULT '' NOT NULL;
+
+-- +goose Down
+ALTER TABLE todos DROP COLUMN notes;
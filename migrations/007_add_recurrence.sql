@@ -0,0 +1,6 @@
+-- +goose Up
+-- Recurrence rule in RRULE form, e.g. FREQ=WEEKLY;BYDAY=MO; empty means none
+ALTER TABLE todos ADD COLUMN recurrence TEXT DEFAULT '' NOT NULL;
+
+-- +goose Down
+ALTER TABLE todos DROP COLUMN recurrence;
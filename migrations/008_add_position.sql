@@ -0,0 +1,18 @@
+-- +goose Up
+-- Manual ordering; lower positions sort first
+ALTER TABLE todos ADD COLUMN position INTEGER DEFAULT 0 NOT NULL;
+
+-- Seed positions from the existing priority order
+UPDATE todos SET position = (
+    SELECT ranked.rn FROM (
+        SELECT id, ROW_NUMBER() OVER (ORDER BY priority ASC, created_at DESC, id ASC) AS rn FROM todos
+    ) AS ranked
+    WHERE ranked.id = todos.id
+);
+
+-- Index for the manual ordering
+CREATE INDEX IF NOT EXISTS idx_todos_position ON todos(position);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_position;
+ALTER TABLE todos DROP COLUMN position;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Soft deletion; trashed todos keep the time they were deleted
+ALTER TABLE todos ADD COLUMN deleted_at DATETIME;
+
+-- Index for listing and purging the trash
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos(deleted_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_deleted_at;
+ALTER TABLE todos DROP COLUMN deleted_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- When a todo was completed; NULL while it is active
+ALTER TABLE todos ADD COLUMN completed_at DATETIME;
+
+-- Backfill from the last update, the best record of completion so far
+UPDATE todos SET completed_at = updated_at WHERE completed = TRUE;
+
+-- Index for the completed tab's ordering
+CREATE INDEX IF NOT EXISTS idx_todos_completed_at ON todos(completed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_completed_at;
+ALTER TABLE todos DROP COLUMN completed_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Append-only history of todo changes. There is no foreign key so a todo's
+-- history outlives it once purged.
+CREATE TABLE IF NOT EXISTS todo_events (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL,
+    kind TEXT CHECK (kind IN ('create', 'content', 'priority', 'toggle', 'delete', 'restore', 'purge')) NOT NULL,
+    old_value TEXT DEFAULT '' NOT NULL,
+    new_value TEXT DEFAULT '' NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- Index for a todo's history
+CREATE INDEX IF NOT EXISTS idx_todo_events_todo_id ON todo_events(todo_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todo_events_todo_id;
+DROP TABLE IF EXISTS todo_events;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- Workflow state; completed stays in step, TRUE exactly when status is 'done'
+ALTER TABLE todos ADD COLUMN status TEXT CHECK (status IN ('todo', 'doing', 'blocked', 'done')) DEFAULT 'todo' NOT NULL;
+
+-- Backfill completed todos
+UPDATE todos SET status = 'done' WHERE completed = TRUE;
+
+-- Index for filtering by status
+CREATE INDEX IF NOT EXISTS idx_todos_status ON todos(status);
+
+-- SQLite can't alter a CHECK constraint, so rebuild todo_events to allow
+-- status events
+CREATE TABLE todo_events_new (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL,
+    kind TEXT CHECK (kind IN ('create', 'content', 'priority', 'toggle', 'status', 'delete', 'restore', 'purge')) NOT NULL,
+    old_value TEXT DEFAULT '' NOT NULL,
+    new_value TEXT DEFAULT '' NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+INSERT INTO todo_events_new SELECT id, todo_id, kind, old_value, new_value, created_at FROM todo_events;
+DROP TABLE todo_events;
+ALTER TABLE todo_events_new RENAME TO todo_events;
+CREATE INDEX IF NOT EXISTS idx_todo_events_todo_id ON todo_events(todo_id);
+
+-- +goose Down
+CREATE TABLE todo_events_old (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL,
+    kind TEXT CHECK (kind IN ('create', 'content', 'priority', 'toggle', 'delete', 'restore', 'purge')) NOT NULL,
+    old_value TEXT DEFAULT '' NOT NULL,
+    new_value TEXT DEFAULT '' NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+INSERT INTO todo_events_old SELECT id, todo_id, kind, old_value, new_value, created_at FROM todo_events WHERE kind != 'status';
+DROP TABLE todo_events;
+ALTER TABLE todo_events_old RENAME TO todo_events;
+CREATE INDEX IF NOT EXISTS idx_todo_events_todo_id ON todo_events(todo_id);
+DROP INDEX IF EXISTS idx_todos_status;
+ALTER TABLE todos DROP COLUMN status;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Columns of the kanban board, in display order. A wip_limit of 0 means no
+-- limit.
+CREATE TABLE IF NOT EXISTS board_columns (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    position INTEGER DEFAULT 0 NOT NULL,
+    wip_limit INTEGER DEFAULT 0 NOT NULL
+);
+
+-- Starting columns; users can rename, remove, or add to them
+INSERT INTO board_columns (name, position) VALUES ('backlog', 1), ('doing', 2), ('review', 3);
+
+-- The board column a todo sits in; NULL puts it in the first column
+ALTER TABLE todos ADD COLUMN column_id INTEGER REFERENCES board_columns(id) ON DELETE SET NULL;
+
+-- Index for counting a column's cards
+CREATE INDEX IF NOT EXISTS idx_todos_column_id ON todos(column_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_column_id;
+ALTER TABLE todos DROP COLUMN column_id;
+DROP TABLE IF EXISTS board_columns;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Blocked-by links: todo_id can't start until blocker_id is done
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    blocker_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id != blocker_id)
+);
+
+-- Index for finding a blocker's dependents
+CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocker_id ON todo_dependencies(blocker_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todo_dependencies_blocker_id;
+DROP TABLE IF EXISTS todo_dependencies;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Tracked time: one row per timer run, with stopped_at NULL while it runs
+CREATE TABLE IF NOT EXISTS time_entries (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    started_at DATETIME NOT NULL,
+    stopped_at DATETIME
+);
+
+-- Index for summing a todo's time
+CREATE INDEX IF NOT EXISTS idx_time_entries_todo_id ON time_entries(todo_id);
+
+-- Index for reports over a date range
+CREATE INDEX IF NOT EXISTS idx_time_entries_started_at ON time_entries(started_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_time_entries_started_at;
+DROP INDEX IF EXISTS idx_time_entries_todo_id;
+DROP TABLE IF EXISTS time_entries;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Named filter queries shown as tabs
+CREATE TABLE IF NOT EXISTS saved_views (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    query TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS saved_views;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- The sort chosen for each tab, keyed like "active", "complete", or "project:3"
+CREATE TABLE IF NOT EXISTS tab_sorts (
+    tab TEXT PRIMARY KEY NOT NULL,
+    mode TEXT NOT NULL,
+    descending BOOLEAN DEFAULT FALSE NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS tab_sorts;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- The recurring todo whose completion created this one, so completing it
+-- again after reopening it doesn't create a second next occurrence
+ALTER TABLE todos ADD COLUMN recurrence_source_id INTEGER REFERENCES todos(id) ON DELETE SET NULL;
+
+-- Index for finding a todo's next occurrence
+CREATE INDEX IF NOT EXISTS idx_todos_recurrence_source_id ON todos(recurrence_source_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_todos_recurrence_source_id;
+ALTER TABLE todos DROP COLUMN recurrence_source_id;
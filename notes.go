@@ -0,0 +1,143 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// noteEditorHeight is how many lines of notes the editor shows at once.
+const noteEditorHeight = 10
+
+// noteEditor is a small multi-line text buffer for a todo's notes. Cursor
+// columns count runes, not bytes. Edits copy the touched lines, so an editor
+// held by an earlier State is never changed underneath it.
+type noteEditor struct {
+	lines [][]rune
+	row   int
+	col   int
+	top   int
+}
+
+func newNoteEditor(text string) noteEditor {
+	var e noteEditor
+	for _, line := range strings.Split(text, "\n") {
+		e.lines = append(e.lines, []rune(line))
+	}
+	e.row = len(e.lines) - 1
+	e.col = len(e.lines[e.row])
+	return e.scroll()
+}
+
+// Text joins the buffer back into a single string, dropping trailing blank
+// lines.
+func (e noteEditor) Text() string {
+	lines := make([]string, len(e.lines))
+	for i, line := range e.lines {
+		lines[i] = string(line)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\n")
+}
+
+// scroll keeps the cursor row inside the visible window.
+func (e noteEditor) scroll() noteEditor {
+	if e.row < e.top {
+		e.top = e.row
+	}
+	if e.row >= e.top+noteEditorHeight {
+		e.top = e.row - noteEditorHeight + 1
+	}
+	return e
+}
+
+func (e noteEditor) insert(text []rune) noteEditor {
+	e.lines = slices.Clone(e.lines)
+	line := slices.Clone(e.lines[e.row])
+	e.lines[e.row] = slices.Insert(line, e.col, text...)
+	e.col += len(text)
+	return e
+}
+
+// newline splits the current line at the cursor.
+func (e noteEditor) newline() noteEditor {
+	line := e.lines[e.row]
+	head := slices.Clone(line[:e.col])
+	tail := slices.Clone(line[e.col:])
+	e.lines = slices.Clone(e.lines)
+	e.lines[e.row] = head
+	e.lines = slices.Insert(e.lines, e.row+1, tail)
+	e.row++
+	e.col = 0
+	return e.scroll()
+}
+
+// backspace deletes the rune before the cursor, joining with the previous
+// line at the start of a line.
+func (e noteEditor) backspace() noteEditor {
+	switch {
+	case e.col > 0:
+		e.lines = slices.Clone(e.lines)
+		line := slices.Clone(e.lines[e.row])
+		e.lines[e.row] = slices.Delete(line, e.col-1, e.col)
+		e.col--
+	case e.row > 0:
+		prev := e.lines[e.row-1]
+		joined := append(slices.Clone(prev), e.lines[e.row]...)
+		e.lines = slices.Clone(e.lines)
+		e.lines[e.row-1] = joined
+		e.lines = slices.Delete(e.lines, e.row, e.row+1)
+		e.row--
+		e.col = len(prev)
+	}
+	return e.scroll()
+}
+
+// moveRow moves the cursor up or down by n lines, clamping the column to
+// the new line's length.
+func (e noteEditor) moveRow(n int) noteEditor {
+	e.row = min(max(e.row+n, 0), len(e.lines)-1)
+	e.col = min(e.col, len(e.lines[e.row]))
+	return e.scroll()
+}
+
+// moveCol moves the cursor left or right, wrapping across line ends.
+func (e noteEditor) moveCol(n int) noteEditor {
+	e.col += n
+	switch {
+	case e.col < 0 && e.row > 0:
+		e.row--
+		e.col = len(e.lines[e.row])
+	case e.col < 0:
+		e.col = 0
+	case e.col > len(e.lines[e.row]) && e.row < len(e.lines)-1:
+		e.row++
+		e.col = 0
+	case e.col > len(e.lines[e.row]):
+		e.col = len(e.lines[e.row])
+	}
+	return e.scroll()
+}
+
+func (e noteEditor) home() noteEditor {
+	e.col = 0
+	return e
+}
+
+func (e noteEditor) end() noteEditor {
+	e.col = len(e.lines[e.row])
+	return e
+}
+
+// visible returns the lines in the scroll window with a block cursor drawn
+// at the cursor position.
+func (e noteEditor) visible() []string {
+	var out []string
+	for i := e.top; i < min(e.top+noteEditorHeight, len(e.lines)); i++ {
+		line := e.lines[i]
+		if i == e.row {
+			out = append(out, string(line[:e.col])+"█"+string(line[e.col:]))
+		} else {
+			out = append(out, string(line))
+		}
+	}
+	return out
+}
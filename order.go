@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// SortMode is the key a list of todos is ordered by.
+type SortMode string
+
+const (
+	SortPriority  SortMode = "priority"
+	SortDue       SortMode = "due"
+	SortManual    SortMode = "manual"
+	SortCreated   SortMode = "created"
+	SortUpdated   SortMode = "updated"
+	SortAlpha     SortMode = "alpha"
+	SortAge       SortMode = "age"
+	SortCompleted SortMode = "completed"
+)
+
+// sortModes are the keys the open todo tabs cycle through, and
+// completedSortModes those of the complete tab.
+var (
+	sortModes          = []SortMode{SortPriority, SortDue, SortManual, SortCreated, SortUpdated, SortAlpha, SortAge}
+	completedSortModes = []SortMode{SortCompleted, SortPriority, SortCreated, SortUpdated, SortAlpha, SortAge}
+)
+
+// Sort is a sort key and the direction to list it in. Ascending puts P0,
+// the earliest dates, A, and the youngest todos first.
+type Sort struct {
+	Mode SortMode
+	Desc bool
+}
+
+// defaultSort is the order a tab starts in: most urgent first for open
+// todos, and most recently completed first for the complete tab.
+func defaultSort(completed bool) Sort {
+	if completed {
+		return Sort{Mode: SortCompleted, Desc: true}
+	}
+	return Sort{Mode: SortPriority}
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return string(s.Mode) + " desc"
+	}
+	return string(s.Mode) + " asc"
+}
+
+// next cycles to the following sort key in modes, keeping the direction.
+func (s Sort) next(modes []SortMode) Sort {
+	i := slices.Index(modes, s.Mode)
+	s.Mode = modes[(i+1)%len(modes)]
+	return s
+}
+
+// sortTerm is one expression of an ORDER BY. A fixed term keeps its
+// direction when the sort is reversed, so undated todos stay last.
+type sortTerm struct {
+	expr  string
+	desc  bool
+	fixed bool
+}
+
+// terms lists the ORDER BY expressions for the sort, ending with id so the
+// order is total.
+func (s Sort) terms() []sortTerm {
+	var terms []sortTerm
+	switch s.Mode {
+	case SortDue:
+		terms = []sortTerm{{expr: "due_at IS NULL", fixed: true}, {expr: "due_at"}, {expr: "priority"}, {expr: "created_at", desc: true}}
+	case SortManual:
+		terms = []sortTerm{{expr: "position"}}
+	case SortCreated:
+		terms = []sortTerm{{expr: "created_at"}}
+	case SortUpdated:
+		terms = []sortTerm{{expr: "updated_at"}}
+	case SortAlpha:
+		terms = []sortTerm{{expr: "content COLLATE NOCASE"}}
+	case SortAge:
+		// How long the todo was open: until it was completed, or until the
+		// time the listing was taken, so every page measures to the same now.
+		terms = []sortTerm{{expr: "COALESCE(julianday(completed_at), listing.at) - julianday(created_at)"}}
+	case SortCompleted:
+		terms = []sortTerm{{expr: "completed_at"}}
+	default:
+		terms = []sortTerm{{expr: "priority"}, {expr: "created_at", desc: true}}
+	}
+	terms = append(terms, sortTerm{expr: "id"})
+	if s.Desc {
+		for i := range terms {
+			if !terms[i].fixed {
+				terms[i].desc = !terms[i].desc
+			}
+		}
+	}
+	return terms
+}
+
+// orderBy renders the sort as an ORDER BY clause.
+func (s Sort) orderBy() string {
+	var parts []string
+	for _, t := range s.terms() {
+		if t.desc {
+			parts = append(parts, t.expr+" DESC")
+		} else {
+			parts = append(parts, t.expr+" ASC")
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// todoPageSize is how many todos a tab loads at a time.
+const todoPageSize = 200
+
+// ListTodos lists the active or completed todos matching the filter in the
+// given order, at most limit of them (all if limit is 0), starting after the
+// todo with id after (from the top if 0). Pages are found by keyset: the next page is the rows
+// whose sort key comes after that todo's, so reading deep into a long list
+// costs no more than reading its start. Ages are measured to now, which the
+// pages of one listing must share or the order would drift between them.
+// The ORDER BY is built from the
+// sort's fixed terms, so no input reaches the SQL. sqlc can't generate a
+// query this dynamic, so the columns are read by orm.ScanTodo, which lives
+// beside the generated orm.Todo in internal/orm/scan.go.
+func (d *Database) ListTodos(ctx context.Context, completed bool, filter Filter, sort Sort, now time.Time, after, limit int) ([]orm.Todo, error) {
+	query := "WITH listing(at) AS (SELECT julianday(?)) SELECT " + orm.TodoColumns + " FROM todos, listing"
+	args := []any{now}
+	if after != 0 {
+		keyset, keys := sort.keyset()
+		query += " JOIN (SELECT " + keys + " FROM todos, listing WHERE id = ?) AS cursor ON " + keyset
+		args = append(args, after)
+	}
+	query += " WHERE completed = ? AND deleted_at IS NULL"
+	args = append(args, completed)
+	for _, clause := range filter.where {
+		query += " AND " + clause
+	}
+	query += sort.orderBy()
+	args = append(args, filter.args...)
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	return d.queryTodos(ctx, query, args...)
+}
+
+// ListTodoTrees pages through a tab by its top-level todos, those whose
+// parent isn't in the tab, reading up to limit of them as ListTodos does.
+// Along with them come all of their subtasks the tab holds, in the same
+// order, so a subtask never shows up at the top level of one page only to
+// move under its parent when a later page brings that in.
+func (d *Database) ListTodoTrees(ctx context.Context, completed bool, filter Filter, sort Sort, now time.Time, after, limit int) (roots, subtasks []orm.Todo, err error) {
+	tab, tabArgs := filter.and("completed = ?", completed).SQL()
+	top := filter.and("(parent_id IS NULL OR parent_id NOT IN ("+tab+"))", tabArgs...)
+	roots, err = d.ListTodos(ctx, completed, top, sort, now, after, limit)
+	if err != nil || len(roots) == 0 {
+		return roots, []orm.Todo{}, err
+	}
+	ids := idList(todoIDs(roots))
+	query := "WITH RECURSIVE listing(at) AS (SELECT julianday(?))," +
+		" tree(tree_id) AS (SELECT value FROM json_each(?) UNION SELECT todos.id FROM todos JOIN tree ON todos.parent_id = tree.tree_id WHERE todos.id IN (" + tab + "))" +
+		" SELECT " + orm.TodoColumns + " FROM todos, listing WHERE id IN (SELECT tree_id FROM tree) AND id NOT IN (SELECT value FROM json_each(?))" +
+		sort.orderBy()
+	args := append([]any{now, ids}, tabArgs...)
+	subtasks, err = d.queryTodos(ctx, query, append(args, ids)...)
+	return roots, subtasks, err
+}
+
+// queryTodos runs a query selecting orm.TodoColumns.
+func (d *Database) queryTodos(ctx context.Context, query string, args ...any) ([]orm.Todo, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	todos := []orm.Todo{}
+	for rows.Next() {
+		t, err := orm.ScanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	return todos, rows.Err()
+}
+
+// idList renders ids as a JSON array, for queries that read a list of ids
+// through json_each(?) so their text stays the same however many there are.
+func idList(ids []int) string {
+	list, _ := json.Marshal(ids)
+	return string(list)
+}
+
+func todoIDs(todos []orm.Todo) []int {
+	ids := make([]int, len(todos))
+	for i, t := range todos {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// keyset returns the condition selecting rows that sort after the cursor
+// row, along with the select list that names the cursor's key values k0, k1,
+// and so on. A row comes after the cursor if it ties on every term before
+// some term and beats it there; IS makes the ties hold for NULLs too.
+func (s Sort) keyset() (string, string) {
+	terms := s.terms()
+	keys := make([]string, len(terms))
+	alternatives := make([]string, len(terms))
+	for i, t := range terms {
+		keys[i] = fmt.Sprintf("(%s) AS k%d", t.expr, i)
+		var conds []string
+		for j := range i {
+			conds = append(conds, fmt.Sprintf("(%s) IS cursor.k%d", terms[j].expr, j))
+		}
+		op := ">"
+		if t.desc {
+			op = "<"
+		}
+		conds = append(conds, fmt.Sprintf("(%s) %s cursor.k%d", t.expr, op, i))
+		alternatives[i] = "(" + strings.Join(conds, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", strings.Join(keys, ", ")
+}
+
+func projectTab(id int) string {
+	return "project:" + strconv.Itoa(id)
+}
+
+func viewTab(id int) string {
+	return "view:" + strconv.Itoa(id)
+}
+
+// TabSorts returns the sort saved for each tab.
+func (d *Database) TabSorts(ctx context.Context) (map[string]Sort, error) {
+	rows, err := d.Queries.ListTabSorts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sorts := make(map[string]Sort, len(rows))
+	for _, row := range rows {
+		sorts[row.Tab] = Sort{Mode: SortMode(row.Mode), Desc: row.Descending}
+	}
+	return sorts, nil
+}
+
+// SetTabSort remembers the sort chosen for a tab.
+func (d *Database) SetTabSort(ctx context.Context, tab string, sort Sort) error {
+	return d.Queries.SetTabSort(ctx, orm.SetTabSortParams{
+		Tab:        tab,
+		Mode:       string(sort.Mode),
+		Descending: sort.Desc,
+	})
+}
+
+// Reorder arranges the given todos in the order listed. The positions they
+// already hold are handed out again in that order, so todos outside the list
+// keep their place. All positions are rewritten in a single transaction.
+func (d *Database) Reorder(ctx context.Context, order []int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	positions := make([]int, 0, len(order))
+	for _, id := range order {
+		todo, err := q.GetTodo(ctx, id)
+		if err != nil {
+			return err
+		}
+		positions = append(positions, todo.Position)
+	}
+	slices.Sort(positions)
+	for i, id := range order {
+		err := q.SetTodoPosition(ctx, orm.SetTodoPositionParams{ID: id, Position: positions[i]})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
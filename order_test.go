@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func TestSortNext(t *testing.T) {
+	s := Sort{Mode: SortAge, Desc: true}.next(sortModes)
+	if s != (Sort{Mode: SortPriority, Desc: true}) {
+		t.Errorf("next after age = %s, want priority desc", s)
+	}
+	// A mode the tab doesn't offer starts the cycle over.
+	if s := (Sort{Mode: SortCompleted}).next(sortModes); s.Mode != SortPriority {
+		t.Errorf("next after completed = %s, want priority", s)
+	}
+}
+
+func TestSortOrderBy(t *testing.T) {
+	tests := []struct {
+		sort Sort
+		want string
+	}{
+		{Sort{Mode: SortPriority}, " ORDER BY priority ASC, created_at DESC, id ASC"},
+		{Sort{Mode: SortPriority, Desc: true}, " ORDER BY priority DESC, created_at ASC, id DESC"},
+		// Undated todos stay last in both directions.
+		{Sort{Mode: SortDue, Desc: true}, " ORDER BY due_at IS NULL ASC, due_at DESC, priority DESC, created_at ASC, id DESC"},
+		{Sort{Mode: SortAlpha}, " ORDER BY content COLLATE NOCASE ASC, id ASC"},
+	}
+	for _, tt := range tests {
+		if got := tt.sort.orderBy(); got != tt.want {
+			t.Errorf("%s: orderBy() = %q, want %q", tt.sort, got, tt.want)
+		}
+	}
+}
+
+func TestTodoColumns(t *testing.T) {
+	d := newTestDatabase(t)
+	rows, err := d.db.Query("SELECT name FROM pragma_table_info('todos') ORDER BY cid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		columns = append(columns, name)
+	}
+	if got := strings.Join(columns, ", "); got != orm.TodoColumns {
+		t.Errorf("todos has columns\n\t%s\nbut orm.TodoColumns is\n\t%s", got, orm.TodoColumns)
+	}
+	if n := reflect.TypeFor[orm.Todo]().NumField(); n != len(columns) {
+		t.Errorf("orm.Todo has %d fields for %d columns", n, len(columns))
+	}
+}
+
+// seedSortTodos adds todos with plenty of ties and missing values, so every
+// sort key needs its tie-breakers.
+func seedSortTodos(t *testing.T, d *Database) {
+	t.Helper()
+	ctx := context.Background()
+	base := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.Local)
+	for i := range 40 {
+		var due sql.NullTime
+		if i%3 != 0 {
+			due = sql.NullTime{Time: base.AddDate(0, 0, i%5), Valid: true}
+		}
+		todo, err := d.Queries.CreateTodo(ctx, orm.CreateTodoParams{
+			Content:   fmt.Sprintf("%c todo", 'a'+i%4),
+			Priority:  string([]Priority{P0, P1, P2}[i%3]),
+			CreatedAt: base.Add(time.Duration(i%6) * time.Hour),
+			UpdatedAt: base.Add(time.Duration(i%7) * time.Hour),
+			DueAt:     due,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Queries.SetTodoPosition(ctx, orm.SetTodoPositionParams{Position: i % 8, ID: todo.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if i%4 == 0 {
+			err := d.Queries.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{
+				CompletedAt: sql.NullTime{Time: base.AddDate(0, 0, 10+i%3), Valid: true},
+				UpdatedAt:   base,
+				ID:          todo.ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestListTodosPages(t *testing.T) {
+	d := newTestDatabase(t)
+	seedSortTodos(t, d)
+	ctx := context.Background()
+	now := time.Now()
+	for _, completed := range []bool{false, true} {
+		modes := sortModes
+		if completed {
+			modes = completedSortModes
+		}
+		for _, mode := range modes {
+			for _, desc := range []bool{false, true} {
+				sort := Sort{Mode: mode, Desc: desc}
+				all, err := d.ListTodos(ctx, completed, Filter{}, sort, now, 0, 0)
+				if err != nil {
+					t.Fatalf("%s: %v", sort, err)
+				}
+				// Reading in pages must give the same rows in the same
+				// order as reading everything at once.
+				var paged []orm.Todo
+				after := 0
+				for range len(all) + 1 {
+					page, err := d.ListTodos(ctx, completed, Filter{}, sort, now, after, 7)
+					if err != nil {
+						t.Fatalf("%s after %d: %v", sort, after, err)
+					}
+					paged = append(paged, page...)
+					if len(page) < 7 {
+						break
+					}
+					after = page[len(page)-1].ID
+				}
+				if !slices.Equal(todoIDs(paged), todoIDs(all)) {
+					t.Errorf("completed=%t %s: pages give\n\t%v\nwant\n\t%v", completed, sort, todoIDs(paged), todoIDs(all))
+				}
+			}
+		}
+	}
+}
+
+// TestListTodosAgeAt checks that ages run to the time the listing was taken,
+// for the todos that have no completion time to stop at.
+func TestListTodosAgeAt(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	created := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	var ids []int
+	for _, content := range []string{"completed", "undated"} {
+		todo, err := d.Queries.CreateTodo(ctx, orm.CreateTodoParams{Content: content, Priority: string(P1), CreatedAt: created, UpdatedAt: created})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, todo.ID)
+	}
+	err := d.Queries.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{
+		CompletedAt: sql.NullTime{Time: created.AddDate(0, 0, 3), Valid: true},
+		UpdatedAt:   created,
+		ID:          ids[0],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Todos completed before completion times were kept have none.
+	if _, err := d.db.ExecContext(ctx, "UPDATE todos SET completed = TRUE WHERE id = ?", ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		now  time.Time
+		want []int
+	}{
+		{created.AddDate(0, 0, 1), []int{ids[1], ids[0]}},
+		{created.AddDate(0, 0, 5), []int{ids[0], ids[1]}},
+	}
+	for _, tt := range tests {
+		sort := Sort{Mode: SortAge}
+		todos, err := d.ListTodos(ctx, true, Filter{}, sort, tt.now, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := todoIDs(todos); !slices.Equal(got, tt.want) {
+			t.Errorf("by age at %s = %v, want %v", tt.now.Format(time.DateOnly), got, tt.want)
+		}
+		page, err := d.ListTodos(ctx, true, Filter{}, sort, tt.now, tt.want[0], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := todoIDs(page); !slices.Equal(got, tt.want[1:]) {
+			t.Errorf("by age at %s after #%d = %v, want %v", tt.now.Format(time.DateOnly), tt.want[0], got, tt.want[1:])
+		}
+	}
+}
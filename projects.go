@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+const maxProjectNameLength = 32
+
+// NormalizeProjectName trims a project name and checks it fits in a tab.
+func NormalizeProjectName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errors.New("project name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > maxProjectNameLength {
+		return "", fmt.Errorf("project name must be at most %d characters", maxProjectNameLength)
+	}
+	return name, nil
+}
+
+// CreateProject adds a project, rejecting names that are already taken.
+func (d *Database) CreateProject(ctx context.Context, name string) (orm.Project, error) {
+	if _, err := d.Queries.GetProjectByName(ctx, name); err == nil {
+		return orm.Project{}, fmt.Errorf("project %q already exists", name)
+	}
+	return d.Queries.CreateProject(ctx, orm.CreateProjectParams{
+		Name:      name,
+		CreatedAt: time.Now(),
+	})
+}
+
+// projectName returns the name of the project with the given id, or "".
+func projectName(projects []orm.Project, id sql.NullInt64) string {
+	if !id.Valid {
+		return ""
+	}
+	for _, p := range projects {
+		if int64(p.ID) == id.Int64 {
+			return p.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// Frequencies understood in a recurrence rule.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// Recurrence is the subset of an RFC 5545 RRULE that todos can repeat on:
+// a frequency with an optional interval, weekdays for weekly rules, and a
+// day of the month (-1 for the last day) for monthly rules.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+}
+
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var unitFreqs = map[string]string{"day": FreqDaily, "week": FreqWeekly, "month": FreqMonthly, "year": FreqYearly}
+
+var everyPattern = regexp.MustCompile(`^every (\d+) (day|week|month|year)s?$`)
+
+// ParseRecurrence reads a recurrence rule. It accepts daily, weekly,
+// monthly, yearly, weekdays, "every 2 weeks", weekday lists such as
+// "weekly mon,fri" or "every tue", "monthly 15" or "monthly last", and
+// RRULEs such as FREQ=WEEKLY;INTERVAL=2;BYDAY=MO.
+func ParseRecurrence(input string) (Recurrence, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if strings.Contains(s, "freq=") {
+		return parseRRule(input)
+	}
+	r := Recurrence{Interval: 1}
+	switch s {
+	case "daily", "every day":
+		r.Freq = FreqDaily
+		return r, nil
+	case "weekly", "every week":
+		r.Freq = FreqWeekly
+		return r, nil
+	case "weekdays", "every weekday":
+		r.Freq = FreqWeekly
+		r.ByDay = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		return r, nil
+	case "monthly", "every month":
+		r.Freq = FreqMonthly
+		return r, nil
+	case "yearly", "annually", "every year":
+		r.Freq = FreqYearly
+		return r, nil
+	}
+	if m := everyPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 {
+			return r, fmt.Errorf("recurrence interval must be at least 1")
+		}
+		r.Interval = n
+		r.Freq = unitFreqs[m[2]]
+		return r, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "monthly "); ok {
+		rest = strings.TrimPrefix(rest, "on ")
+		r.Freq = FreqMonthly
+		if rest == "last" {
+			r.ByMonthDay = -1
+			return r, nil
+		}
+		day, err := strconv.Atoi(strings.TrimRight(rest, "stndrh"))
+		if err != nil || day < 1 || day > 31 {
+			return r, fmt.Errorf("invalid day of month %q (use 1-31 or last)", rest)
+		}
+		r.ByMonthDay = day
+		return r, nil
+	}
+	for _, prefix := range []string{"weekly on ", "weekly ", "every "} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			days, err := parseWeekdays(rest)
+			if err != nil {
+				return r, err
+			}
+			r.Freq = FreqWeekly
+			r.ByDay = days
+			return r, nil
+		}
+	}
+	return r, fmt.Errorf("can't understand recurrence %q (try daily, weekly mon,fri, monthly 15, or FREQ=WEEKLY;BYDAY=MO)", input)
+}
+
+// parseWeekdays reads a comma or space separated list of weekday names.
+func parseWeekdays(input string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	for _, f := range fields {
+		if f == "and" {
+			continue
+		}
+		found := false
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if f == name || f == name[:3] || f == name+"s" {
+				if !slices.Contains(days, d) {
+					days = append(days, d)
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday %q", f)
+		}
+	}
+	if len(days) == 0 {
+		return nil, errors.New("no weekdays given")
+	}
+	slices.Sort(days)
+	return days, nil
+}
+
+func parseRRule(input string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+	rule := strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(input)), "RRULE:")
+	for part := range strings.SplitSeq(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = value
+			default:
+				return r, fmt.Errorf("unsupported RRULE frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("invalid RRULE interval %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for day := range strings.SplitSeq(value, ",") {
+				i := slices.Index(rruleDays, day)
+				if i < 0 {
+					return r, fmt.Errorf("unsupported RRULE day %q", day)
+				}
+				if !slices.Contains(r.ByDay, time.Weekday(i)) {
+					r.ByDay = append(r.ByDay, time.Weekday(i))
+				}
+			}
+			slices.Sort(r.ByDay)
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n == 0 || n < -1 || n > 31 {
+				return r, fmt.Errorf("unsupported RRULE month day %q (use 1-31 or -1)", value)
+			}
+			r.ByMonthDay = n
+		default:
+			return r, fmt.Errorf("unsupported RRULE part %q", key)
+		}
+	}
+	if r.Freq == "" {
+		return r, errors.New("RRULE is missing FREQ")
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return r, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if r.ByMonthDay != 0 && r.Freq != FreqMonthly {
+		return r, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	return r, nil
+}
+
+// String renders the rule as an RRULE, which is how it is stored.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = rruleDays[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%d", r.ByMonthDay))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe renders the rule for people, e.g. "weekly on mon, fri" or
+// "every 2 months on the 15th".
+func (r Recurrence) Describe() string {
+	unit := map[string]string{FreqDaily: "day", FreqWeekly: "week", FreqMonthly: "month", FreqYearly: "year"}[r.Freq]
+	label := strings.ToLower(r.Freq)
+	if r.Interval > 1 {
+		label = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = strings.ToLower(d.String()[:3])
+		}
+		label += " on " + strings.Join(days, ", ")
+	}
+	switch {
+	case r.ByMonthDay == -1:
+		label += " on the last day"
+	case r.ByMonthDay > 0:
+		label += " on the " + ordinal(r.ByMonthDay)
+	}
+	return label
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// maxRecurrenceYears bounds the search for a monthly rule's next day. The
+// months a rule visits and the leap years among them repeat within this
+// span, so a rule with no occurrence by then never has one.
+const maxRecurrenceYears = 8
+
+// Next returns the first occurrence strictly after the day of from, keeping
+// from's time of day. Monthly and yearly rules without a fixed day repeat on
+// from's day, clamped to the end of shorter months. It fails for a monthly
+// rule whose day never falls in the months it visits, such as
+// FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31 from a month of 30 days.
+func (r Recurrence) Next(from time.Time) (time.Time, error) {
+	interval := max(r.Interval, 1)
+	switch r.Freq {
+	case FreqDaily:
+		return from.AddDate(0, 0, interval), nil
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return from.AddDate(0, 0, 7*interval), nil
+		}
+		start := weekStart(from)
+		for d := from.AddDate(0, 0, 1); ; d = d.AddDate(0, 0, 1) {
+			weeks := daysBetween(start, weekStart(d)) / 7
+			if weeks%interval == 0 && slices.Contains(r.ByDay, d.Weekday()) {
+				return d, nil
+			}
+		}
+	case FreqMonthly:
+		if r.ByMonthDay == 0 {
+			return addMonthsClamped(from, interval, from.Day()), nil
+		}
+		for k := 0; k <= 12*interval*maxRecurrenceYears; k += interval {
+			// Months too short for the day are skipped, as RFC 5545 does.
+			first := time.Date(from.Year(), from.Month()+time.Month(k), 1,
+				from.Hour(), from.Minute(), from.Second(), 0, from.Location())
+			last := first.AddDate(0, 1, -1).Day()
+			day := r.ByMonthDay
+			if day == -1 {
+				day = last
+			}
+			if day > last {
+				continue
+			}
+			d := first.AddDate(0, 0, day-1)
+			if daysBetween(from, d) > 0 {
+				return d, nil
+			}
+		}
+		return from, fmt.Errorf("%s never falls on a day after %s", r.Describe(), from.Format("2006-01-02"))
+	case FreqYearly:
+		return addMonthsClamped(from, 12*interval, from.Day()), nil
+	}
+	return from, fmt.Errorf("unsupported recurrence frequency %q", r.Freq)
+}
+
+// weekStart returns the Monday starting t's week, the RRULE default WKST.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func addMonthsClamped(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, last)-1)
+}
+
+// nextDue picks the due date of a recurring todo's next occurrence. It
+// follows the old due date when there was one, skipping occurrences that are
+// already in the past, and otherwise counts from today. Setting a rule checks
+// it with nextDue too, since whether a monthly day ever occurs depends on the
+// month the rule starts from.
+func nextDue(r Recurrence, due time.Time, hasDue bool, now time.Time) (time.Time, error) {
+	today := startOfDay(now)
+	if !hasDue {
+		return r.Next(today)
+	}
+	next, err := r.Next(due)
+	for err == nil && next.Before(today) {
+		next, err = r.Next(next)
+	}
+	return next, err
+}
+
+// ToggleTodo flips a todo between active and done. Completing a recurring
+// todo also creates its next occurrence, which is returned; otherwise the
+// returned todo is nil.
+func (d *Database) ToggleTodo(ctx context.Context, id int) (*orm.Todo, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	next, err := toggle(ctx, d.Queries.WithTx(tx), id, true, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return next, tx.Commit()
+}
+
+// createNextOccurrence copies a recurring todo, with its notes and tags, due
+// on the rule's next date. The copy remembers the todo it came from.
+func createNextOccurrence(ctx context.Context, q *orm.Queries, todo orm.Todo, now time.Time) (*orm.Todo, error) {
+	rule, err := ParseRecurrence(todo.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("todo #%d: %w", todo.ID, err)
+	}
+	due, err := nextDue(rule, todo.DueAt.Time, todo.DueAt.Valid, now)
+	if err != nil {
+		return nil, fmt.Errorf("todo #%d: %w", todo.ID, err)
+	}
+	next, err := q.CreateTodo(ctx, orm.CreateTodoParams{
+		Content:   todo.Content,
+		Priority:  todo.Priority,
+		CreatedAt: now,
+		UpdatedAt: now,
+		DueAt:     sql.NullTime{Time: due, Valid: true},
+		ProjectID: todo.ProjectID,
+		ParentID:  todo.ParentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := logEvent(ctx, q, next.ID, EventCreate, "", next.Content, now); err != nil {
+		return nil, err
+	}
+	err = q.SetTodoRecurrence(ctx, orm.SetTodoRecurrenceParams{
+		ID:         next.ID,
+		Recurrence: todo.Recurrence,
+		UpdatedAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	next.Recurrence = todo.Recurrence
+	err = q.SetTodoRecurrenceSource(ctx, orm.SetTodoRecurrenceSourceParams{
+		RecurrenceSourceID: nullID(todo.ID),
+		ID:                 next.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	next.RecurrenceSourceID = nullID(todo.ID)
+	if todo.Notes != "" {
+		err = q.UpdateTodoNotes(ctx, orm.UpdateTodoNotesParams{
+			ID:        next.ID,
+			Notes:     todo.Notes,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		next.Notes = todo.Notes
+	}
+	tags, err := q.GetTodoTags(ctx, todo.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		err = q.AttachTag(ctx, orm.AttachTagParams{TodoID: next.ID, TagID: tag.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &next, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"daily", "FREQ=DAILY"},
+		{"every 2 weeks", "FREQ=WEEKLY;INTERVAL=2"},
+		{"weekdays", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"weekly fri, mon", "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{"monthly 15th", "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"monthly last", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"yearly", "FREQ=YEARLY"},
+		{"RRULE:FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31", "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.input)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tt.input, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseRecurrence(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+	for _, input := range []string{"", "fortnightly", "every 0 days", "monthly 32", "FREQ=HOURLY", "FREQ=DAILY;BYDAY=MO", "INTERVAL=2"} {
+		if _, err := ParseRecurrence(input); err == nil {
+			t.Errorf("ParseRecurrence(%q) should fail", input)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		rule string
+		from string
+		want string
+	}{
+		{"daily", "2026-02-28", "2026-03-01"},
+		{"every 3 days", "2026-01-30", "2026-02-02"},
+		{"weekly", "2026-10-18", "2026-10-25"},
+		{"weekly mon,fri", "2026-10-16", "2026-10-19"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", "2026-10-19", "2026-11-02"},
+		{"monthly", "2026-01-31", "2026-02-28"},
+		{"monthly 31", "2026-04-15", "2026-05-31"},
+		{"monthly last", "2028-02-10", "2028-02-29"},
+		{"FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31", "2026-01-15", "2026-01-31"},
+		{"FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=29", "2027-02-01", "2028-02-29"},
+		{"yearly", "2028-02-29", "2029-02-28"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", tt.rule, err)
+		}
+		got, err := r.Next(day(tt.from))
+		if err != nil {
+			t.Errorf("%s from %s: %v", tt.rule, tt.from, err)
+			continue
+		}
+		if want := day(tt.want); !got.Equal(want) {
+			t.Errorf("%s from %s = %s, want %s", tt.rule, tt.from, got.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+func TestRecurrenceNextNeverOccurs(t *testing.T) {
+	// Every twelfth month from April is April, which has no 31st.
+	r, err := ParseRecurrence("FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, time.April, 15, 0, 0, 0, 0, time.Local)
+	if got, err := r.Next(from); err == nil {
+		t.Errorf("Next(%s) = %s, want an error", from.Format("2006-01-02"), got.Format("2006-01-02"))
+	}
+	if _, err := nextDue(r, from, true, from); err == nil {
+		t.Error("nextDue should pass on the error")
+	}
+}
+
+// TestToggleTodoSpawnsOnce checks that completing a recurring todo again
+// after reopening it doesn't create a second next occurrence, unless the
+// first one was thrown away.
+func TestToggleTodoSpawnsOnce(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todo := createTestTodos(t, d, "water plants")[0]
+	err := d.Queries.SetTodoRecurrence(ctx, orm.SetTodoRecurrenceParams{Recurrence: "FREQ=DAILY", UpdatedAt: time.Now(), ID: todo.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	toggle := func() *orm.Todo {
+		t.Helper()
+		next, err := d.ToggleTodo(ctx, todo.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return next
+	}
+	next := toggle()
+	if next == nil || next.RecurrenceSourceID.Int64 != int64(todo.ID) {
+		t.Fatalf("completing spawned %+v, want an occurrence of #%d", next, todo.ID)
+	}
+	if again := toggle(); again != nil {
+		t.Errorf("reopening spawned #%d", again.ID)
+	}
+	if again := toggle(); again != nil {
+		t.Errorf("completing again spawned #%d besides #%d", again.ID, next.ID)
+	}
+	if n, _ := d.Queries.CountActiveTodos(ctx); n != 1 {
+		t.Errorf("%d open todos, want just the one occurrence", n)
+	}
+
+	if err := d.Trash(ctx, next.ID); err != nil {
+		t.Fatal(err)
+	}
+	toggle()
+	if again := toggle(); again == nil {
+		t.Error("completing after the occurrence was trashed spawned nothing")
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pressly/goose/v3"
+)
+
+// The search index is a Go migration rather than a .sql file because FTS5 is
+// only compiled into go-sqlite3 with the sqlite_fts5 build tag. Without it
+// the index falls back to FTS4, which answers the same prefix MATCH queries,
+// so a plain go build or go install still opens every database. The index
+// keeps its own copy of each todo's content, so one set of triggers keeps
+// either module in sync.
+func init() {
+	goose.AddNamedMigrationContext("016_add_todo_search.go", upTodoSearch, downTodoSearch)
+}
+
+func upTodoSearch(ctx context.Context, tx *sql.Tx) error {
+	var fts5 bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pragma_module_list WHERE name = 'fts5')").Scan(&fts5)
+	if err != nil {
+		return err
+	}
+	table := "CREATE VIRTUAL TABLE todos_fts USING fts4 (content, tokenize=unicode61)"
+	if fts5 {
+		table = "CREATE VIRTUAL TABLE todos_fts USING fts5 (content)"
+	}
+	statements := []string{
+		table,
+		`CREATE TRIGGER todos_fts_insert AFTER INSERT ON todos BEGIN
+			INSERT INTO todos_fts (rowid, content) VALUES (new.id, new.content);
+		END`,
+		`CREATE TRIGGER todos_fts_update AFTER UPDATE OF content ON todos BEGIN
+			UPDATE todos_fts SET content = new.content WHERE rowid = new.id;
+		END`,
+		`CREATE TRIGGER todos_fts_delete AFTER DELETE ON todos BEGIN
+			DELETE FROM todos_fts WHERE rowid = old.id;
+		END`,
+		"INSERT INTO todos_fts (rowid, content) SELECT id, content FROM todos",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downTodoSearch(ctx context.Context, tx *sql.Tx) error {
+	for _, stmt := range []string{
+		"DROP TRIGGER IF EXISTS todos_fts_delete",
+		"DROP TRIGGER IF EXISTS todos_fts_update",
+		"DROP TRIGGER IF EXISTS todos_fts_insert",
+		"DROP TABLE IF EXISTS todos_fts",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchTerms splits a query into lowercase words the way the index's
+// tokenizer does, so punctuation can't be mistaken for MATCH syntax.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// SearchTodos finds active and completed todos containing every word of
+// query, treating each word as a prefix so results narrow as it is typed. It
+// maps each hit's id to whether that todo is completed.
+func (d *Database) SearchTodos(ctx context.Context, query string) (map[int]bool, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return map[int]bool{}, nil
+	}
+	rows, err := d.Queries.SearchTodos(ctx, strings.Join(terms, "* ")+"*")
+	if err != nil {
+		return nil, err
+	}
+	hits := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		hits[row.ID] = row.Completed
+	}
+	return hits, nil
+}
+
+// highlightMatches renders text in style, picking out the words that start
+// with one of terms in searchMatchStyle.
+func highlightMatches(text string, terms []string, style lipgloss.Style) string {
+	var b strings.Builder
+	plain := 0
+	prev := ' '
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		wordStart := !unicode.IsLetter(prev) && !unicode.IsNumber(prev)
+		prev = r
+		if !wordStart {
+			i += size
+			continue
+		}
+		n := 0
+		for _, term := range terms {
+			if len(term) > n && i+len(term) <= len(text) && strings.EqualFold(text[i:i+len(term)], term) {
+				n = len(term)
+			}
+		}
+		if n == 0 {
+			i += size
+			continue
+		}
+		if plain < i {
+			b.WriteString(style.Render(text[plain:i]))
+		}
+		b.WriteString(searchMatchStyle.Render(text[i : i+n]))
+		i += n
+		plain = i
+		prev, _ = utf8.DecodeLastRuneInString(text[:i])
+	}
+	if plain < len(text) {
+		b.WriteString(style.Render(text[plain:]))
+	}
+	return b.String()
+}
+
+// runSearch looks up the hits for the current query.
+func (s State) runSearch() tea.Cmd {
+	query := s.search
+	return tea.Cmd(func() tea.Msg {
+		hits, err := s.database.SearchTodos(context.Background(), query)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error searching: %v", err))
+		}
+		return searchResultMsg{query: query, hits: hits}
+	})
+}
+
+// revealHits expands collapsed parents so every hit in the loaded tab is on
+// screen for n and N to reach.
+func (s State) revealHits() State {
+	parents := make(map[int]int, len(s.loaded))
+	for _, todo := range s.loaded {
+		if todo.ParentID.Valid {
+			parents[todo.ID] = int(todo.ParentID.Int64)
+		}
+	}
+	for _, todo := range s.loaded {
+		if _, ok := s.searchHits[todo.ID]; !ok {
+			continue
+		}
+		for id := parents[todo.ID]; id != 0; id = parents[id] {
+			if s.collapsed[id] {
+				s.collapsed = withCollapsed(s.collapsed, id, false)
+			}
+		}
+	}
+	s.todos, s.tree = flattenTree(s.loaded, s.collapsed)
+	return s
+}
+
+// searchTab returns the other searchable tab when it holds hits: complete
+// when browsing open todos, and active when browsing completed ones.
+func (s State) searchTab() (ViewMode, bool) {
+	want := s.listingActive()
+	for _, completed := range s.searchHits {
+		if completed == want {
+			if want {
+				return CompletedView, true
+			}
+			return ActiveView, true
+		}
+	}
+	return s.viewMode, false
+}
+
+// hitIndex finds the next hit in direction dir, starting with the cursor's
+// own row when from is 0, or returns -1 if there is none before the end of
+// the list.
+func (s State) hitIndex(dir, from int) int {
+	for i := s.cursor + dir*from; i >= 0 && i < len(s.todos); i += dir {
+		if _, ok := s.searchHits[s.todos[i].ID]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextHit moves the cursor to the next hit in direction dir. Running off the
+// end of the list carries on in the other searchable tab if it has hits, and
+// wraps around otherwise.
+func (s State) nextHit(dir int) (State, tea.Cmd) {
+	if i := s.hitIndex(dir, 1); i >= 0 {
+		s.cursor = i
+		return s, nil
+	}
+	if tab, ok := s.searchTab(); ok {
+		return s.seekTab(tab, dir)
+	}
+	return s.seekFirstHit(dir), nil
+}
+
+// showHits moves the cursor to the first hit at or after it as the query is
+// typed, wrapping within the tab and only leaving it when it has no hits.
+func (s State) showHits() (State, tea.Cmd) {
+	if i := s.hitIndex(1, 0); i >= 0 {
+		s.cursor = i
+		return s, nil
+	}
+	for _, todo := range s.todos {
+		if _, ok := s.searchHits[todo.ID]; ok {
+			return s.seekFirstHit(1), nil
+		}
+	}
+	if tab, ok := s.searchTab(); ok {
+		return s.seekTab(tab, 1)
+	}
+	return s, nil
+}
+
+// seekTab switches to tab, landing on its first hit once it loads.
+func (s State) seekTab(tab ViewMode, dir int) (State, tea.Cmd) {
+	s.viewMode = tab
+	s.projectID = 0
+	s.cursor = 0
+	s.seekHit = dir
+	return s, s.loadTodos()
+}
+
+// seekFirstHit puts the cursor on the first hit in the tab, or the last when
+// searching backwards.
+func (s State) seekFirstHit(dir int) State {
+	for step := range s.todos {
+		i := step
+		if dir < 0 {
+			i = len(s.todos) - 1 - step
+		}
+		if _, ok := s.searchHits[s.todos[i].ID]; ok {
+			s.cursor = i
+			break
+		}
+	}
+	return s
+}
+
+// searchActive reports whether n and N step between search hits.
+func (s State) searchActive() bool {
+	return s.search != "" && s.viewMode != TrashView
+}
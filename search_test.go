@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"testing"
+)
+
+// TestSearchTodos checks the index the search migration builds, whichever
+// full text module go-sqlite3 was compiled with.
+func TestSearchTodos(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todos := createTestTodos(t, d, "deploy the api", "write deployment notes", "buy milk", "deploy docs")
+	if err := d.UpdateContent(ctx, todos[2].ID, "buy oat milk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Trash(ctx, todos[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"dep", []int{todos[0].ID, todos[1].ID}},
+		{"DEPLOY api", []int{todos[0].ID}},
+		{"oat", []int{todos[2].ID}},
+		{`"milk" (oat*`, []int{todos[2].ID}},
+		{"docs", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		hits, err := d.SearchTodos(ctx, tt.query)
+		if err != nil {
+			t.Errorf("SearchTodos(%q): %v", tt.query, err)
+			continue
+		}
+		want := map[int]bool{}
+		for _, id := range tt.want {
+			want[id] = false
+		}
+		if !maps.Equal(hits, want) {
+			t.Errorf("SearchTodos(%q) = %v, want %v", tt.query, hits, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+-- name: CreateColumn :one
+INSERT INTO board_columns (name, position)
+VALUES (?, (SELECT COALESCE(MAX(position), 0) + 1 FROM board_columns))
+RETURNING id, name, position, wip_limit;
+
+-- name: GetColumnByName :one
+SELECT id, name, position, wip_limit
+FROM board_columns
+WHERE name = ?;
+
+-- name: ListColumns :many
+SELECT id, name, position, wip_limit
+FROM board_columns
+ORDER BY position ASC, id ASC;
+
+-- name: RenameColumn :exec
+UPDATE board_columns
+SET name = ?
+WHERE id = ?;
+
+-- name: SetColumnWipLimit :exec
+UPDATE board_columns
+SET wip_limit = ?
+WHERE id = ?;
+
+-- name: DeleteColumn :exec
+DELETE FROM board_columns WHERE id = ?;
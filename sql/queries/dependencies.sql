@@ -0,0 +1,41 @@
+-- name: AddDependency :exec
+INSERT OR IGNORE INTO todo_dependencies (todo_id, blocker_id)
+VALUES (?, ?);
+
+-- name: RemoveDependency :exec
+DELETE FROM todo_dependencies
+WHERE todo_id = ? AND blocker_id = ?;
+
+-- name: CountDependencyPath :one
+WITH RECURSIVE chain (id) AS (
+    SELECT dep.blocker_id FROM todo_dependencies AS dep WHERE dep.todo_id = ?
+    UNION
+    SELECT dep.blocker_id FROM todo_dependencies AS dep JOIN chain ON dep.todo_id = chain.id
+)
+SELECT COUNT(*) FROM chain WHERE id = ?;
+
+-- name: GetOpenBlockers :many
+SELECT dep.todo_id, dep.blocker_id
+FROM todo_dependencies AS dep
+JOIN todos AS blocker ON blocker.id = dep.blocker_id
+WHERE dep.todo_id IN (SELECT value FROM json_each(sqlc.arg(ids)))
+  AND blocker.completed = FALSE AND blocker.deleted_at IS NULL
+ORDER BY dep.todo_id ASC, dep.blocker_id ASC;
+
+-- name: CountOpenBlockers :one
+SELECT COUNT(*)
+FROM todo_dependencies AS dep
+JOIN todos AS blocker ON blocker.id = dep.blocker_id
+WHERE dep.todo_id = ? AND blocker.completed = FALSE AND blocker.deleted_at IS NULL;
+
+-- name: GetBlockers :many
+SELECT blocker_id
+FROM todo_dependencies
+WHERE todo_id = ?
+ORDER BY blocker_id ASC;
+
+-- name: GetDependents :many
+SELECT todo_id
+FROM todo_dependencies
+WHERE blocker_id = ?
+ORDER BY todo_id ASC;
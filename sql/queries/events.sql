@@ -0,0 +1,9 @@
+-- name: InsertTodoEvent :exec
+INSERT INTO todo_events (todo_id, kind, old_value, new_value, created_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetTodoEvents :many
+SELECT id, todo_id, kind, old_value, new_value, created_at
+FROM todo_events
+WHERE todo_id = ?
+ORDER BY created_at DESC, id DESC;
@@ -0,0 +1,25 @@
+-- name: CreateProject :one
+INSERT INTO projects (name, created_at)
+VALUES (?, ?)
+RETURNING id, name, created_at;
+
+-- name: GetProjectByName :one
+SELECT id, name, created_at
+FROM projects
+WHERE name = ?;
+
+-- name: ListProjects :many
+SELECT id, name, created_at
+FROM projects
+ORDER BY created_at ASC, id ASC;
+
+-- name: RenameProject :exec
+UPDATE projects
+SET name = ?
+WHERE id = ?;
+
+-- name: DeleteProject :exec
+DELETE FROM projects WHERE id = ?;
+
+-- name: CountActiveProjectTodos :one
+SELECT COUNT(*) FROM todos WHERE completed = FALSE AND deleted_at IS NULL AND project_id = ?;
@@ -0,0 +1,6 @@
+-- name: SearchTodos :many
+SELECT todos.id, todos.completed
+FROM todos_fts
+JOIN todos ON todos.id = todos_fts.rowid
+WHERE todos_fts MATCH ? AND todos.deleted_at IS NULL
+ORDER BY todos.id ASC;
@@ -0,0 +1,11 @@
+-- name: ListTabSorts :many
+SELECT tab, mode, descending
+FROM tab_sorts;
+
+-- name: SetTabSort :exec
+INSERT INTO tab_sorts (tab, mode, descending)
+VALUES (?, ?, ?)
+ON CONFLICT (tab) DO UPDATE SET mode = excluded.mode, descending = excluded.descending;
+
+-- name: DeleteTabSort :exec
+DELETE FROM tab_sorts WHERE tab = ?;
@@ -0,0 +1,31 @@
+-- name: UpsertTag :one
+INSERT INTO tags (name)
+VALUES (?)
+ON CONFLICT (name) DO UPDATE SET name = excluded.name
+RETURNING id, name;
+
+-- name: AttachTag :exec
+INSERT OR IGNORE INTO todo_tags (todo_id, tag_id)
+VALUES (?, ?);
+
+-- name: DetachTag :exec
+DELETE FROM todo_tags
+WHERE todo_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?);
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE id NOT IN (SELECT tag_id FROM todo_tags);
+
+-- name: GetTodoTags :many
+SELECT tags.id, tags.name
+FROM tags
+JOIN todo_tags ON todo_tags.tag_id = tags.id
+WHERE todo_tags.todo_id = ?
+ORDER BY tags.name ASC;
+
+-- name: GetTagsForTodos :many
+SELECT todo_tags.todo_id, tags.name
+FROM todo_tags
+JOIN tags ON tags.id = todo_tags.tag_id
+WHERE todo_tags.todo_id IN (SELECT value FROM json_each(sqlc.arg(ids)))
+ORDER BY tags.name ASC;
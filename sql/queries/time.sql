@@ -0,0 +1,33 @@
+-- name: StartTimeEntry :one
+INSERT INTO time_entries (todo_id, started_at)
+VALUES (?, ?)
+RETURNING id, todo_id, started_at, stopped_at;
+
+-- name: GetRunningTimeEntry :one
+SELECT id, todo_id, started_at, stopped_at
+FROM time_entries
+WHERE stopped_at IS NULL
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: StopRunningTimeEntries :exec
+UPDATE time_entries
+SET stopped_at = ?
+WHERE stopped_at IS NULL;
+
+-- name: StopTodoTimeEntries :exec
+UPDATE time_entries
+SET stopped_at = ?
+WHERE todo_id = ? AND stopped_at IS NULL;
+
+-- name: GetTrackedSeconds :many
+SELECT todo_id, CAST(SUM((julianday(stopped_at) - julianday(started_at)) * 86400) AS INTEGER) AS seconds
+FROM time_entries
+WHERE todo_id IN (SELECT value FROM json_each(sqlc.arg(ids))) AND stopped_at IS NOT NULL
+GROUP BY todo_id;
+
+-- name: GetTimeEntriesSince :many
+SELECT id, todo_id, started_at, stopped_at
+FROM time_entries
+WHERE stopped_at IS NULL OR julianday(stopped_at) > julianday(?)
+ORDER BY started_at ASC;
@@ -1,19 +1,24 @@
 -- name: CreateTodo :one
-INSERT INTO todos (content, priority, created_at, updated_at)
-VALUES (?, ?, ?, ?)
-RETURNING id, content, priority, completed, created_at, updated_at;
+INSERT INTO todos (content, priority, created_at, updated_at, due_at, project_id, parent_id, position)
+VALUES (?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MIN(position), 1) - 1 FROM todos))
+RETURNING id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id;
+
+-- name: GetTodo :one
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
+WHERE id = ?;
 
 -- name: GetActiveTodos :many
-SELECT id, content, priority, completed, created_at, updated_at 
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id 
 FROM todos 
-WHERE completed = FALSE 
+WHERE completed = FALSE AND deleted_at IS NULL
 ORDER BY priority ASC, created_at DESC;
 
 -- name: GetCompletedTodos :many
-SELECT id, content, priority, completed, created_at, updated_at 
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id 
 FROM todos 
-WHERE completed = TRUE 
-ORDER BY updated_at DESC;
+WHERE completed = TRUE AND deleted_at IS NULL
+ORDER BY completed_at DESC, id DESC;
 
 -- name: UpdateTodoContent :exec
 UPDATE todos 
@@ -25,16 +30,144 @@ UPDATE todos
 SET priority = ?, updated_at = ? 
 WHERE id = ?;
 
+-- name: SetTodoDueAt :exec
+UPDATE todos
+SET due_at = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: ClearTodoDueAt :exec
+UPDATE todos
+SET due_at = NULL, updated_at = ?
+WHERE id = ?;
+
+-- name: UpdateTodoNotes :exec
+UPDATE todos
+SET notes = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: SetTodoRecurrence :exec
+UPDATE todos
+SET recurrence = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: SetTodoRecurrenceSource :exec
+UPDATE todos
+SET recurrence_source_id = ?
+WHERE id = ?;
+
+-- name: CountNextOccurrences :one
+SELECT COUNT(*)
+FROM todos
+WHERE recurrence_source_id = ? AND deleted_at IS NULL;
+
+-- name: SetTodoStatus :exec
+UPDATE todos
+SET status = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: SetTodoColumn :exec
+UPDATE todos
+SET column_id = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: SetTodoPosition :exec
+UPDATE todos
+SET position = ?
+WHERE id = ?;
+
+-- name: MoveTodoToProject :exec
+UPDATE todos
+SET project_id = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: SetTodoParent :exec
+UPDATE todos
+SET parent_id = ?, updated_at = ?
+WHERE id = ?;
+
+-- name: GetTodoDescendants :many
+WITH RECURSIVE descendants (id) AS (
+    SELECT child.id FROM todos AS child WHERE child.parent_id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN descendants ON child.parent_id = descendants.id
+)
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
+WHERE deleted_at IS NULL AND id IN (SELECT id FROM descendants)
+ORDER BY priority ASC, created_at DESC;
+
+-- name: CompleteTodoDescendants :exec
+WITH RECURSIVE descendants (id) AS (
+    SELECT child.id FROM todos AS child WHERE child.parent_id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN descendants ON child.parent_id = descendants.id
+)
+UPDATE todos
+SET completed = TRUE, status = 'done', completed_at = ?, updated_at = ?
+WHERE completed = FALSE AND deleted_at IS NULL AND id IN (SELECT id FROM descendants);
+
+-- name: GetSubtaskProgress :many
+SELECT parent_id, COUNT(*) AS total, COUNT(CASE WHEN completed THEN 1 END) AS done
+FROM todos
+WHERE parent_id IN (SELECT value FROM json_each(sqlc.arg(ids))) AND deleted_at IS NULL
+GROUP BY parent_id;
+
 -- name: ToggleTodoCompleted :exec
-UPDATE todos 
-SET completed = NOT completed, updated_at = ? 
+UPDATE todos
+SET completed = NOT completed,
+    status = CASE WHEN completed THEN 'todo' ELSE 'done' END,
+    completed_at = CASE WHEN completed THEN NULL ELSE ? END,
+    updated_at = ?
 WHERE id = ?;
 
+-- name: TrashTodo :exec
+WITH RECURSIVE subtree (id) AS (
+    SELECT todos.id FROM todos WHERE todos.id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN subtree ON child.parent_id = subtree.id
+)
+UPDATE todos
+SET deleted_at = ?
+WHERE deleted_at IS NULL AND id IN (SELECT id FROM subtree);
+
+-- name: RestoreTodo :exec
+WITH RECURSIVE subtree (id) AS (
+    SELECT todos.id FROM todos WHERE todos.id = ?
+    UNION ALL
+    SELECT child.id FROM todos AS child JOIN subtree ON child.parent_id = subtree.id
+)
+UPDATE todos
+SET deleted_at = NULL
+WHERE id IN (SELECT id FROM subtree);
+
+-- name: DetachFromTrashedParent :exec
+UPDATE todos
+SET parent_id = NULL
+WHERE id = ? AND parent_id IN (SELECT parent.id FROM todos AS parent WHERE parent.deleted_at IS NOT NULL);
+
+-- name: GetTrashedTodos :many
+SELECT id, content, priority, completed, created_at, updated_at, due_at, project_id, parent_id, notes, recurrence, position, deleted_at, completed_at, status, column_id, recurrence_source_id
+FROM todos
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC;
+
 -- name: DeleteTodo :exec
 DELETE FROM todos WHERE id = ?;
 
+-- name: GetTrashedBefore :many
+SELECT id FROM todos WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?) ORDER BY id ASC;
+
+-- name: PurgeTrashedBefore :execrows
+DELETE FROM todos WHERE deleted_at IS NOT NULL AND julianday(deleted_at) < julianday(?);
+
+-- name: EmptyTrash :execrows
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+
 -- name: CountActiveTodos :one
-SELECT COUNT(*) FROM todos WHERE completed = FALSE;
+SELECT COUNT(*) FROM todos WHERE completed = FALSE AND deleted_at IS NULL;
 
 -- name: CountCompletedTodos :one
-SELECT COUNT(*) FROM todos WHERE completed = TRUE;
+SELECT COUNT(*) FROM todos WHERE completed = TRUE AND deleted_at IS NULL;
+
+-- name: CountTrashedTodos :one
+SELECT COUNT(*) FROM todos WHERE deleted_at IS NOT NULL;
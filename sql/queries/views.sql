@@ -0,0 +1,22 @@
+-- name: CreateView :one
+INSERT INTO saved_views (name, query, created_at)
+VALUES (?, ?, ?)
+RETURNING id, name, query, created_at;
+
+-- name: GetViewByName :one
+SELECT id, name, query, created_at
+FROM saved_views
+WHERE name = ?;
+
+-- name: ListViews :many
+SELECT id, name, query, created_at
+FROM saved_views
+ORDER BY created_at ASC, id ASC;
+
+-- name: RenameView :exec
+UPDATE saved_views
+SET name = ?
+WHERE id = ?;
+
+-- name: DeleteView :exec
+DELETE FROM saved_views WHERE id = ?;
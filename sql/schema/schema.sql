@@ -1,11 +1,116 @@
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE board_columns (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    position INTEGER DEFAULT 0 NOT NULL,
+    wip_limit INTEGER DEFAULT 0 NOT NULL
+);
+
 CREATE TABLE todos (
     id INTEGER PRIMARY KEY NOT NULL,
     content TEXT NOT NULL,
     priority TEXT CHECK (priority IN ('P0', 'P1', 'P2')) DEFAULT 'P2' NOT NULL,
     completed BOOLEAN DEFAULT FALSE NOT NULL,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    due_at DATETIME,
+    project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL,
+    parent_id INTEGER REFERENCES todos (id) ON DELETE CASCADE,
+    notes TEXT DEFAULT '' NOT NULL,
+    recurrence TEXT DEFAULT '' NOT NULL,
+    position INTEGER DEFAULT 0 NOT NULL,
+    deleted_at DATETIME,
+    completed_at DATETIME,
+    status TEXT CHECK (status IN ('todo', 'doing', 'blocked', 'done')) DEFAULT 'todo' NOT NULL,
+    column_id INTEGER REFERENCES board_columns (id) ON DELETE SET NULL,
+    recurrence_source_id INTEGER REFERENCES todos (id) ON DELETE SET NULL
 );
 
 CREATE INDEX idx_todos_completed ON todos (completed);
 CREATE INDEX idx_todos_priority ON todos (priority);
+CREATE INDEX idx_todos_due_at ON todos (due_at);
+CREATE INDEX idx_todos_project_id ON todos (project_id);
+CREATE INDEX idx_todos_parent_id ON todos (parent_id);
+CREATE INDEX idx_todos_position ON todos (position);
+CREATE INDEX idx_todos_deleted_at ON todos (deleted_at);
+CREATE INDEX idx_todos_completed_at ON todos (completed_at);
+CREATE INDEX idx_todos_status ON todos (status);
+CREATE INDEX idx_todos_column_id ON todos (column_id);
+CREATE INDEX idx_todos_recurrence_source_id ON todos (recurrence_source_id);
+
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE todo_tags (
+    todo_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+CREATE TABLE todo_events (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL,
+    kind TEXT CHECK (kind IN ('create', 'content', 'priority', 'toggle', 'status', 'delete', 'restore', 'purge')) NOT NULL,
+    old_value TEXT DEFAULT '' NOT NULL,
+    new_value TEXT DEFAULT '' NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_todo_events_todo_id ON todo_events (todo_id);
+
+CREATE TABLE todo_dependencies (
+    todo_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    blocker_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id != blocker_id)
+);
+
+CREATE INDEX idx_todo_dependencies_blocker_id ON todo_dependencies (blocker_id);
+
+CREATE TABLE time_entries (
+    id INTEGER PRIMARY KEY NOT NULL,
+    todo_id INTEGER NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    started_at DATETIME NOT NULL,
+    stopped_at DATETIME
+);
+
+CREATE INDEX idx_time_entries_todo_id ON time_entries (todo_id);
+CREATE INDEX idx_time_entries_started_at ON time_entries (started_at);
+
+-- Migration 016 creates this with fts4 instead when go-sqlite3 is built
+-- without FTS5; both answer the same MATCH queries.
+CREATE VIRTUAL TABLE todos_fts USING fts5 (content);
+
+CREATE TRIGGER todos_fts_insert AFTER INSERT ON todos BEGIN
+    INSERT INTO todos_fts (rowid, content) VALUES (new.id, new.content);
+END;
+
+CREATE TRIGGER todos_fts_update AFTER UPDATE OF content ON todos BEGIN
+    UPDATE todos_fts SET content = new.content WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER todos_fts_delete AFTER DELETE ON todos BEGIN
+    DELETE FROM todos_fts WHERE rowid = old.id;
+END;
+
+CREATE TABLE saved_views (
+    id INTEGER PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    query TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE tab_sorts (
+    tab TEXT PRIMARY KEY NOT NULL,
+    mode TEXT NOT NULL,
+    descending BOOLEAN DEFAULT FALSE NOT NULL
+);
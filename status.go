@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// Status is where a todo sits in its workflow. A todo is completed exactly
+// when its status is StatusDone.
+type Status string
+
+const (
+	StatusTodo    Status = "todo"
+	StatusDoing   Status = "doing"
+	StatusBlocked Status = "blocked"
+	StatusDone    Status = "done"
+)
+
+var statuses = []Status{StatusTodo, StatusDoing, StatusBlocked, StatusDone}
+
+// ParseStatus validates a status name.
+func ParseStatus(s string) (Status, error) {
+	if !slices.Contains(statuses, Status(s)) {
+		return StatusTodo, fmt.Errorf("invalid status %q (want todo, doing, blocked, or done)", s)
+	}
+	return Status(s), nil
+}
+
+// nextFilter cycles the active tab's status filter through the open states,
+// where "" shows every state.
+func nextFilter(s Status) Status {
+	switch s {
+	case "":
+		return StatusTodo
+	case StatusTodo:
+		return StatusDoing
+	case StatusDoing:
+		return StatusBlocked
+	}
+	return ""
+}
+
+// SetStatus moves a todo to a new workflow state. Moving into or out of done
+// toggles completion as well, so completing a recurring todo this way also
+// creates its next occurrence, which is returned.
+func (d *Database) SetStatus(ctx context.Context, id int, status Status) (*orm.Todo, error) {
+	return d.setStatus(ctx, id, status, true)
+}
+
+// setStatus is SetStatus with control over spawning the next occurrence, for
+// undo to replay.
+func (d *Database) setStatus(ctx context.Context, id int, status Status, spawn bool) (*orm.Todo, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	todo, err := q.GetTodo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	prev := Status(todo.Status)
+	if prev == status {
+		return nil, tx.Commit()
+	}
+	var next *orm.Todo
+	if (prev == StatusDone) != (status == StatusDone) {
+		if next, err = toggle(ctx, q, id, spawn, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := setStatusLogged(ctx, q, todo, status, now); err != nil {
+		return nil, err
+	}
+	return next, tx.Commit()
+}
+
+// setStatusLogged records todo's move to status, leaving completion alone.
+func setStatusLogged(ctx context.Context, q *orm.Queries, todo orm.Todo, status Status, now time.Time) error {
+	err := q.SetTodoStatus(ctx, orm.SetTodoStatusParams{
+		ID:        todo.ID,
+		Status:    string(status),
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	return logEvent(ctx, q, todo.ID, EventStatus, todo.Status, string(status), now)
+}
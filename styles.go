@@ -1,11 +1,14 @@
 package main
 
 import (
-	"context"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
+	"github.com/andrewjmcgehee/godoit/internal/orm"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lucasb-eyer/go-colorful"
 )
@@ -84,6 +87,10 @@ var (
 			MarginTop(1).
 			Padding(0, 1).
 			Background(lipgloss.Color("#2D1B1B"))
+	statusStyle = lipgloss.NewStyle().
+			Foreground(green).
+			MarginTop(1).
+			Padding(0, 1)
 	emptyStyle = lipgloss.NewStyle().
 			Foreground(lightGray).
 			Italic(true).
@@ -92,8 +99,91 @@ var (
 	cursorStyle = lipgloss.NewStyle().
 			Foreground(magenta).
 			Padding(0, 1)
+	profileStyle = lipgloss.NewStyle().
+			Foreground(magenta).
+			Italic(true)
+	dueOverdueStyle = lipgloss.NewStyle().
+			Foreground(red).
+			Bold(true)
+	dueTodayStyle = lipgloss.NewStyle().
+			Foreground(yellow)
+	dueLaterStyle = lipgloss.NewStyle().
+			Foreground(lightGray).
+			Faint(true)
+	projectChipStyle = lipgloss.NewStyle().
+				Foreground(magenta).
+				MarginRight(1)
+	treeStyle = lipgloss.NewStyle().
+			Foreground(lightGray)
+	progressStyle = lipgloss.NewStyle().
+			Foreground(lightGray).
+			MarginRight(1)
+	recurrenceStyle = lipgloss.NewStyle().
+			Foreground(blue).
+			MarginLeft(1)
+	groupHeaderStyle = lipgloss.NewStyle().
+				Foreground(magenta).
+				Bold(true).
+				MarginLeft(4)
+	notesMarkerStyle = lipgloss.NewStyle().
+				Foreground(yellow).
+				MarginRight(1)
+	statusDoingStyle = lipgloss.NewStyle().
+				Foreground(yellow).
+				MarginRight(1)
+	statusBlockedStyle = lipgloss.NewStyle().
+				Foreground(red).
+				Bold(true).
+				MarginRight(1)
+	boardHeaderStyle = lipgloss.NewStyle().
+				Foreground(magenta).
+				Bold(true)
+	boardOverLimitStyle = lipgloss.NewStyle().
+				Foreground(red).
+				Bold(true)
+	blockedItemStyle = lipgloss.NewStyle().
+				Foreground(lightGray).
+				Faint(true).
+				MarginRight(1)
+	blockedHintStyle = lipgloss.NewStyle().
+				Foreground(red).
+				Faint(true).
+				MarginRight(1)
+	trackedStyle = lipgloss.NewStyle().
+			Foreground(lightGray).
+			MarginRight(1)
+	timerRunningStyle = lipgloss.NewStyle().
+				Foreground(green).
+				Bold(true).
+				MarginRight(1)
+	searchMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("0")).
+				Background(yellow)
+	searchPromptStyle = lipgloss.NewStyle().
+				Foreground(yellow).
+				MarginLeft(4)
+	scrollStyle = lipgloss.NewStyle().
+			Foreground(lightGray).
+			MarginLeft(4)
+	tagChipStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("0")).
+			Padding(0, 1).
+			MarginRight(1)
 )
 
+// tagColors are the chip backgrounds, picked per tag by a hash of its name so
+// a tag keeps its colour everywhere it appears.
+var tagColors = []lipgloss.Color{
+	"#F25D94",
+	"#EDFF82",
+	"#643AFF",
+	"#14F9D5",
+	"#FF9F5A",
+	"#7AD3FF",
+	"#B8F28C",
+	"#D39BFF",
+}
+
 func colorGrid(xSteps, ySteps int) [][]string {
 	x0y0, _ := colorful.Hex("#F25D94")
 	x1y0, _ := colorful.Hex("#EDFF82")
@@ -132,7 +222,7 @@ func (s State) renderTitle() string {
 		colorized = append(colorized, strings.Split(line, ""))
 	}
 	colorized = append(colorized, strings.Split(strings.Repeat(" ", s.windowWidth), ""))
-	subtitle := "seriously, just do the thing already..."
+	subtitle := fmt.Sprintf("seriously, just do the thing already... [%s]", s.location.Scope())
 	colors := colorGrid(s.windowWidth, len(colorized))
 	for r := range colorized {
 		for c, char := range colorized[r] {
@@ -181,93 +271,277 @@ func (s State) View() string {
 		b.WriteString(s.renderCreateView())
 	case EditingState:
 		b.WriteString(s.renderEditView())
+	case ProfileState:
+		b.WriteString(s.renderProfileView())
+	case DueState:
+		b.WriteString(s.renderDueView())
+	case RecurrenceState:
+		hint := "daily, weekdays, weekly mon,fri, every 2 weeks, monthly 15, monthly last, FREQ=WEEKLY;BYDAY=MO"
+		if s.editingTodo != nil {
+			hint = s.editingTodo.Content + "\n\n" + hint
+		}
+		b.WriteString(s.renderForm("repeat", hint, [][2]string{
+			{"enter", "save (empty stops)"},
+			{"esc", "cancel"},
+		}))
+	case TagState:
+		b.WriteString(s.renderTagView())
+	case TagFilterState:
+		b.WriteString(s.renderTagFilterView())
+	case FilterState:
+		b.WriteString(s.renderFilterView())
+	case ProjectCreateState:
+		b.WriteString(s.renderForm("new project", "", [][2]string{
+			{"enter", "create project"},
+			{"esc", "cancel"},
+		}))
+	case ProjectRenameState:
+		b.WriteString(s.renderForm("rename project", "", [][2]string{
+			{"enter", "save name"},
+			{"esc", "cancel"},
+		}))
+	case ViewCreateState:
+		b.WriteString(s.renderForm("save view", s.filter.Source, [][2]string{
+			{"enter", "save filter as a tab"},
+			{"esc", "cancel"},
+		}))
+	case ViewRenameState:
+		b.WriteString(s.renderForm("rename view", "", [][2]string{
+			{"enter", "save name"},
+			{"esc", "cancel"},
+		}))
+	case MoveState:
+		b.WriteString(s.renderMoveView())
+	case ColumnsState:
+		b.WriteString(s.renderColumnsView())
+	case ColumnCreateState:
+		b.WriteString(s.renderForm("new column", "", [][2]string{
+			{"enter", "add column"},
+			{"esc", "back"},
+		}))
+	case ColumnRenameState:
+		b.WriteString(s.renderForm("rename column", "", [][2]string{
+			{"enter", "save name"},
+			{"esc", "back"},
+		}))
+	case ColumnLimitState:
+		b.WriteString(s.renderForm("WIP limit", "the most cards the column should hold; empty or 0 for no limit", [][2]string{
+			{"enter", "save limit"},
+			{"esc", "back"},
+		}))
+	case ConfirmState:
+		b.WriteString(s.renderConfirmView())
+	case NotesState:
+		b.WriteString(s.renderNotesView())
+	case HistoryState:
+		b.WriteString(s.renderHistoryView())
+	case LinkState:
+		b.WriteString(s.renderLinkView())
 	default:
 		b.WriteString(s.renderBrowseView())
 	}
-	if s.message != "" {
+	if s.uiState == SearchState {
+		b.WriteString("\n" + s.renderSearchPrompt())
+	} else if s.message != "" {
 		b.WriteString("\n" + messageStyle.Render("⚠ "+s.message))
+	} else if s.status != "" {
+		b.WriteString("\n" + statusStyle.Render(s.status))
 	}
 	return b.String()
 }
 
 func (s State) renderTabs() string {
-	activeTabText := fmt.Sprintf("active: %s", func() string {
-		ctx := context.Background()
-		cnt, err := s.database.Queries.CountActiveTodos(ctx)
-		if err != nil {
-			return "?"
-		}
-		return fmt.Sprintf("%d", cnt)
-	}())
-
-	completedTabText := fmt.Sprintf("complete: %s", func() string {
-		ctx := context.Background()
-		cnt, err := s.database.Queries.CountCompletedTodos(ctx)
-		if err != nil {
-			return "?"
+	counts := s.tabCounts
+	tabs := []string{renderTab(fmt.Sprintf("active: %d", counts.active), s.viewMode == ActiveView)}
+	for _, p := range s.projects {
+		text := fmt.Sprintf("%s: %d", p.Name, counts.projects[p.ID])
+		tabs = append(tabs, renderTab(text, s.viewMode == ProjectView && s.projectID == p.ID))
+	}
+	for _, v := range s.views {
+		text := v.Name + ": ?"
+		if n, ok := counts.views[v.ID]; ok {
+			text = fmt.Sprintf("%s: %d", v.Name, n)
 		}
-		return fmt.Sprintf("%d", cnt)
-	}())
-
-	var activeTabRendered, completedTabRendered string
-	if s.viewMode == ActiveView {
-		activeTabRendered = activeTab.Render(activeTabText)
-		completedTabRendered = tab.Render(completedTabText)
-	} else {
-		activeTabRendered = tab.Render(activeTabText)
-		completedTabRendered = activeTab.Render(completedTabText)
+		tabs = append(tabs, renderTab(text, s.viewMode == SavedView && s.viewID == v.ID))
 	}
+	tabs = append(tabs, renderTab(fmt.Sprintf("complete: %d", counts.completed), s.viewMode == CompletedView))
+	tabs = append(tabs, renderTab(fmt.Sprintf("trash: %d", counts.trashed), s.viewMode == TrashView))
 
-	row := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		activeTabRendered,
-		completedTabRendered,
-	)
+	row := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
 
-	gap := tabGap.Render(strings.Repeat(" ", max(0, s.windowWidth-lipgloss.Width(row)-2)))
+	gapWidth := max(0, s.windowWidth-lipgloss.Width(row)-2)
+	var labels []string
+	if len(s.tagFilter) > 0 {
+		labels = append(labels, profileStyle.Render("tags: "+formatTags(s.tagFilter)))
+	}
+	if s.filter.Active() && s.viewMode != TrashView {
+		labels = append(labels, profileStyle.Render("filter: "+s.filter.Source))
+	}
+	if s.listingActive() && s.statusFilter != "" {
+		labels = append(labels, profileStyle.Render("status: "+string(s.statusFilter)))
+	}
+	if s.listingActive() && s.hideBlocked {
+		labels = append(labels, profileStyle.Render("hiding blocked"))
+	}
+	if s.searchActive() && s.uiState != SearchState {
+		labels = append(labels, profileStyle.Render(fmt.Sprintf("search: %s (%d)", s.search, len(s.searchHits))))
+	}
+	if s.timer != nil {
+		labels = append(labels, timerRunningStyle.Render("⏱ #"+strconv.Itoa(s.timer.TodoID)+" "+formatClock(elapsed(*s.timer, s.now))))
+	}
+	if s.sortable() {
+		labels = append(labels, profileStyle.Render("sort: "+s.currentSort().String()))
+	}
+	var profile string
+	if s.location.Profile != "" {
+		profile = profileStyle.Render("profile: " + s.location.Profile)
+	}
+	label := fitLabels(labels, profile, gapWidth)
+	gap := tabGap.Render(strings.Repeat(" ", gapWidth-lipgloss.Width(label)) + label)
 	row = lipgloss.JoinHorizontal(lipgloss.Bottom, row, gap)
 
 	return row
 }
 
+// fitLabels joins as many labels as fit in width, cutting short the first
+// that overflows. Which database is open matters most, so keep is always
+// shown at the end and the other labels give way to it.
+func fitLabels(labels []string, keep string, width int) string {
+	room := width
+	if keep != "" {
+		room -= lipgloss.Width(keep) + 2
+	}
+	var fitted []string
+	for _, label := range labels {
+		if w := lipgloss.Width(label); w <= room {
+			fitted = append(fitted, label)
+			room -= w + 2
+			continue
+		}
+		if room >= 8 {
+			fitted = append(fitted, lipgloss.NewStyle().MaxWidth(room).Render(label))
+		}
+		break
+	}
+	if keep != "" {
+		fitted = append(fitted, keep)
+	}
+	label := strings.Join(fitted, "  ")
+	if lipgloss.Width(label) > width {
+		if width == 0 {
+			return ""
+		}
+		label = lipgloss.NewStyle().MaxWidth(width).Render(label)
+	}
+	return label
+}
+
+func renderTab(text string, selected bool) string {
+	if selected {
+		return activeTab.Render(text)
+	}
+	return tab.Render(text)
+}
+
 func (s State) renderBrowseView() string {
 	var b strings.Builder
 
 	tabs := s.renderTabs()
 	b.WriteString(tabs + "\n\n")
-	if len(s.todos) == 0 {
+	if s.boardActive() {
+		b.WriteString(s.renderBoard() + "\n")
+	} else if len(s.todos) == 0 {
 		emptyMsg := "😌 nothing here!"
 		if s.viewMode == ActiveView {
 			emptyMsg = "😌 no active todos! press 'n' to create one."
+		} else if s.viewMode == ProjectView {
+			emptyMsg = "😌 nothing in this project! press 'n' to add a todo or 'm' to move one here."
+		} else if s.viewMode == SavedView {
+			emptyMsg = "😌 nothing matches " + viewQuery(s.views, s.viewID)
+		} else if s.viewMode == TrashView && s.database.TrashDays > 0 {
+			emptyMsg = fmt.Sprintf("🗑  trash is empty. deleted todos stay here for %d days.", s.database.TrashDays)
 		}
 		b.WriteString(emptyStyle.Render(emptyMsg) + "\n")
 	} else {
+		now := time.Now()
+		group := ""
+		// lines holds every row of the list; starts and rows index each
+		// todo's first line, group header included, and its own line.
+		var lines []string
+		starts := make([]int, len(s.todos))
+		rows := make([]int, len(s.todos))
 		for i, todo := range s.todos {
+			starts[i] = len(lines)
+			if s.viewMode == CompletedView && s.tree[todo.ID].depth == 0 {
+				if g := completionGroup(todo.CompletedAt, now); g != group {
+					if group != "" {
+						lines = append(lines, "")
+					}
+					group = g
+					lines = append(lines, groupHeaderStyle.Render(group))
+				}
+			}
+			rows[i] = len(lines)
 			cursor := cursorStyle.Render(" ")
 			if i == s.cursor {
 				cursor = cursorStyle.Render("▶︎")
 			}
+			blockers := s.blockers[todo.ID]
 			var content string
-			if i == s.cursor {
+			if len(blockers) > 0 && !todo.Completed {
+				content = blockedItemStyle.Render(fmt.Sprintf("%s: %s", todo.Priority, todo.Content))
+			} else if i == s.cursor {
 				if todo.Completed {
-					content = fmt.Sprintf("%s: %s", todo.Priority, todo.Content)
+					content = fmt.Sprintf("%s: %s", todo.Priority, s.renderContent(todo, selectedCompletedItemStyle))
 					content = selectedCompletedItemStyle.Render(content)
 				} else {
 					priorityText := s.renderPriority(Priority(todo.Priority))
-					content = fmt.Sprintf("%s: %s", priorityText, todo.Content)
+					content = fmt.Sprintf("%s: %s", priorityText, s.renderContent(todo, selectedItemStyle))
 					content = selectedItemStyle.Render(content)
 				}
 			} else {
 				if todo.Completed {
-					content = fmt.Sprintf("%s: %s", todo.Priority, todo.Content)
+					content = fmt.Sprintf("%s: %s", todo.Priority, s.renderContent(todo, completedItemStyle))
 					content = completedItemStyle.Render(content)
 				} else {
 					priorityText := s.renderPriority(Priority(todo.Priority))
-					content = fmt.Sprintf("%s: %s", priorityText, todo.Content)
+					content = fmt.Sprintf("%s: %s", priorityText, s.renderContent(todo, itemStyle))
 					content = itemStyle.Render(content)
 				}
 			}
-			b.WriteString(fmt.Sprintf("%s %s\n", cursor, content))
+			if len(blockers) > 0 && !todo.Completed {
+				content += blockedHintStyle.Render("⊘ blocked by " + formatBlockers(blockers))
+			} else {
+				content += s.renderStatus(todo)
+			}
+			content += s.renderProgress(todo)
+			content += s.renderTracked(todo)
+			if todo.Notes != "" {
+				content += notesMarkerStyle.Render("✎")
+			}
+			if s.viewMode != ProjectView {
+				if name := projectName(s.projects, todo.ProjectID); name != "" {
+					content += projectChipStyle.Render("@" + name)
+				}
+			}
+			content += s.renderTags(todo)
+			if s.viewMode == TrashView {
+				content += dueLaterStyle.Render("deleted " + formatDue(todo.DeletedAt.Time, now))
+			} else {
+				content += s.renderDue(todo)
+			}
+			content += s.renderRecurrence(todo)
+			lines = append(lines, fmt.Sprintf("%s %s%s", cursor, s.renderTreePrefix(todo), content))
+		}
+		detail := ""
+		height := s.listHeight()
+		if s.showDetail && s.cursor < len(s.todos) {
+			detail = s.renderDetail(s.todos[s.cursor])
+			height = max(1, height-lipgloss.Height(detail)-1)
+		}
+		b.WriteString(s.renderViewport(lines, starts, rows, height))
+		if detail != "" {
+			b.WriteString("\n" + detail + "\n")
 		}
 	}
 	mainContent := b.String()
@@ -280,8 +554,11 @@ func (s State) renderBrowseView() string {
 		if i < len(lines) && i < len(helpLines) {
 			mainLine := lines[i]
 			helpLine := helpLines[i]
-			if strings.TrimSpace(helpLine) != "" {
-				result[i] = helpLine
+			if box := strings.TrimLeft(helpLine, " "); box != "" {
+				// Keep the start of the row beside the help box.
+				width := max(0, s.windowWidth-lipgloss.Width(box))
+				mainLine = lipgloss.NewStyle().MaxWidth(width).Render(mainLine)
+				result[i] = mainLine + strings.Repeat(" ", width-lipgloss.Width(mainLine)) + box
 			} else {
 				result[i] = mainLine
 			}
@@ -294,7 +571,99 @@ func (s State) renderBrowseView() string {
 	return strings.Join(result, "\n")
 }
 
+// renderViewport shows height lines of the list starting at the todo at
+// s.offset, moved on if need be to keep the cursor's line in view, with an
+// indicator underneath when the list doesn't fit.
+func (s State) renderViewport(lines []string, starts, rows []int, height int) string {
+	start := starts[min(s.offset, len(starts)-1)]
+	if line := rows[min(s.cursor, len(rows)-1)]; line >= start+height {
+		start = line - height + 1
+	} else if line < start {
+		start = line
+	}
+	end := min(len(lines), start+height)
+	var b strings.Builder
+	for _, line := range lines[start:end] {
+		b.WriteString(line + "\n")
+	}
+	if start == 0 && end == len(lines) && !s.page.more {
+		return b.String()
+	}
+	first, last := len(rows), 0
+	for i, line := range rows {
+		if line >= start && line < end {
+			first, last = min(first, i+1), i+1
+		}
+	}
+	arrows := "  "
+	if start > 0 {
+		arrows = "↑ "
+	}
+	if end < len(lines) || s.page.more {
+		arrows = strings.TrimSpace(arrows+"↓") + " "
+	}
+	total := fmt.Sprintf("%d", len(rows))
+	if s.page.more {
+		total += "+"
+	}
+	b.WriteString(scrollStyle.Render(fmt.Sprintf("%s%d–%d of %s", arrows, first, last, total)) + "\n")
+	return b.String()
+}
+
 func (s State) renderCreateView() string {
+	return s.renderForm("create new todo", "", [][2]string{
+		{"enter", "save todo"},
+		{"esc", "cancel"},
+	})
+}
+
+func (s State) renderEditView() string {
+	return s.renderForm("edit todo", "", [][2]string{
+		{"enter", "save changes"},
+		{"esc", "cancel"},
+	})
+}
+
+func (s State) renderTagView() string {
+	hint := "tags separated by spaces, e.g. #frontend #oncall"
+	if s.editingTodo != nil {
+		hint = s.editingTodo.Content + "\n\n" + hint
+	}
+	return s.renderForm("edit tags", hint, [][2]string{
+		{"enter", "save tags"},
+		{"esc", "cancel"},
+	})
+}
+
+func (s State) renderTagFilterView() string {
+	return s.renderForm("filter by tags", "show todos with any of these tags; empty shows all", [][2]string{
+		{"enter", "apply filter"},
+		{"esc", "cancel"},
+	})
+}
+
+func (s State) renderFilterView() string {
+	hint := "priority:P0,P1 status:doing done:no text:\"deploy\"\ntag:a,b project:name created:<7d due:<3d\n-term negates it; empty shows all"
+	return s.renderForm("filter", hint, [][2]string{
+		{"enter", "apply filter"},
+		{"esc", "cancel"},
+	})
+}
+
+func (s State) renderDueView() string {
+	hint := "today, tomorrow, fri, +3d, 2w, jun 1, 2006-01-02"
+	if s.editingTodo != nil {
+		hint = s.editingTodo.Content + "\n\n" + hint
+	}
+	return s.renderForm("set due date", hint, [][2]string{
+		{"enter", "save (empty clears)"},
+		{"esc", "cancel"},
+	})
+}
+
+// renderForm draws a centred single-line input box around s.editingText with
+// an optional hint underneath and a legend of the form's keys.
+func (s State) renderForm(title, hint string, keys [][2]string) string {
 	formBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(magenta).
@@ -311,6 +680,12 @@ func (s State) renderCreateView() string {
 		Width(50).
 		Border(lipgloss.NormalBorder()).
 		BorderForeground(yellow)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lightGray).
+		Italic(true).
+		Width(50).
+		MarginBottom(1).
+		Align(lipgloss.Center)
 	keymapBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(magenta).
@@ -323,12 +698,16 @@ func (s State) renderCreateView() string {
 		Foreground(lightGray)
 
 	var content []string
-	content = append(content, titleStyle.Render("create new todo"))
+	content = append(content, titleStyle.Render(title))
 	content = append(content, inputFieldStyle.Render(s.editingText+"█"))
+	if hint != "" {
+		content = append(content, hintStyle.Render(hint))
+	}
 
 	var keymaps []string
-	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("save todo")))
-	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("cancel")))
+	for _, k := range keys {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render(k[0]), descStyle.Render(k[1])))
+	}
 	keymapContent := strings.Join(keymaps, "\n")
 
 	content = append(content, keymapBoxStyle.Render(keymapContent))
@@ -347,7 +726,7 @@ func (s State) renderCreateView() string {
 	return form
 }
 
-func (s State) renderEditView() string {
+func (s State) renderProfileView() string {
 	formBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(magenta).
@@ -364,6 +743,9 @@ func (s State) renderEditView() string {
 		Width(50).
 		Border(lipgloss.NormalBorder()).
 		BorderForeground(yellow)
+	listStyle := lipgloss.NewStyle().
+		Width(50).
+		MarginBottom(1)
 	keymapBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(magenta).
@@ -376,11 +758,26 @@ func (s State) renderEditView() string {
 		Foreground(lightGray)
 
 	var content []string
-	content = append(content, titleStyle.Render("edit todo"))
+	content = append(content, titleStyle.Render("switch profile"))
 	content = append(content, inputFieldStyle.Render(s.editingText+"█"))
 
+	var profiles []string
+	for i, name := range s.profiles {
+		cursor := cursorStyle.Render(" ")
+		if i == s.profileCursor && s.editingText == "" {
+			cursor = cursorStyle.Render("▶︎")
+		}
+		line := itemStyle.Render(name)
+		if name == s.location.Profile {
+			line += profileStyle.Render("(current)")
+		}
+		profiles = append(profiles, cursor+line)
+	}
+	content = append(content, listStyle.Render(strings.Join(profiles, "\n")))
+
 	var keymaps []string
-	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("save changes")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑/↓"), descStyle.Render("choose profile")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("switch (typed names are created)")))
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("cancel")))
 	keymapContent := strings.Join(keymaps, "\n")
 
@@ -400,6 +797,572 @@ func (s State) renderEditView() string {
 	return form
 }
 
+// renderMoveView lists the projects the selected todo can be moved into.
+func (s State) renderMoveView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(60).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	listStyle := lipgloss.NewStyle().
+		Width(50).
+		MarginBottom(1)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	title := "move todo"
+	if s.editingTodo != nil {
+		title = "move \"" + s.editingTodo.Content + "\""
+	}
+	content = append(content, titleStyle.Render(title))
+
+	names := []string{"(no project)"}
+	for _, p := range s.projects {
+		names = append(names, p.Name)
+	}
+	var lines []string
+	for i, name := range names {
+		cursor := cursorStyle.Render(" ")
+		if i == s.moveCursor {
+			cursor = cursorStyle.Render("▶︎")
+		}
+		lines = append(lines, cursor+itemStyle.Render(name))
+	}
+	content = append(content, listStyle.Render(strings.Join(lines, "\n")))
+
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑/k ↓/j"), descStyle.Render("choose project")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("move")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("cancel")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderColumnsView draws the board column manager, listing the columns in
+// board order with their card counts and WIP limits.
+func (s State) renderColumnsView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(60).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	listStyle := lipgloss.NewStyle().
+		Width(50).
+		MarginBottom(1)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	content = append(content, titleStyle.Render("board columns"))
+
+	var lines []string
+	for i, column := range s.columns {
+		count := 0
+		if i < len(s.columnCounts) {
+			count = s.columnCounts[i]
+		}
+		cursor := cursorStyle.Render(" ")
+		if i == s.columnCursor {
+			cursor = cursorStyle.Render("▶︎")
+		}
+		line := itemStyle.Render(columnHeader(column, count))
+		if overLimit(column, count) {
+			line = boardOverLimitStyle.Render(columnHeader(column, count) + " ⚠ over limit")
+		}
+		lines = append(lines, cursor+line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, itemStyle.Render("no columns; press n to add one"))
+	}
+	content = append(content, listStyle.Render(strings.Join(lines, "\n")))
+
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑/k ↓/j"), descStyle.Render("choose column")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("n"), descStyle.Render("new column")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("r"), descStyle.Render("rename column")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("w"), descStyle.Render("set WIP limit")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("d"), descStyle.Render("delete column")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("back")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderDetail draws the pane under the list showing the selected todo's
+// notes.
+func (s State) renderDetail(todo orm.Todo) string {
+	width := min(max(s.windowWidth-8, 20), 80)
+	detailBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 1).
+		MarginLeft(3).
+		Width(width)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1)
+	notesStyle := lipgloss.NewStyle().
+		Foreground(gray)
+	metaStyle := lipgloss.NewStyle().
+		Foreground(lightGray).
+		Faint(true).
+		MarginTop(1)
+
+	var content []string
+	content = append(content, titleStyle.Render(todo.Content))
+	if todo.Notes == "" {
+		content = append(content, emptyStyle.Padding(0).Render("no notes. press 'o' to add some."))
+	} else {
+		content = append(content, notesStyle.Render(todo.Notes))
+	}
+	meta := fmt.Sprintf("created %s · updated %s",
+		todo.CreatedAt.Local().Format("Jan 2 15:04"),
+		todo.UpdatedAt.Local().Format("Jan 2 15:04"))
+	content = append(content, metaStyle.Render(meta))
+	return detailBoxStyle.Render(strings.Join(content, "\n"))
+}
+
+// renderNotesView draws the multi-line notes editor with a scroll position
+// indicator.
+func (s State) renderNotesView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(76).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	inputFieldStyle := lipgloss.NewStyle().
+		Foreground(gray).
+		Padding(0, 1).
+		Width(66).
+		Height(noteEditorHeight).
+		Align(lipgloss.Left).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(yellow)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lightGray).
+		Italic(true).
+		Width(66).
+		MarginBottom(1).
+		Align(lipgloss.Right)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	title := "notes"
+	if s.editingTodo != nil {
+		title = "notes for \"" + s.editingTodo.Content + "\""
+	}
+	content = append(content, titleStyle.Render(title))
+	content = append(content, inputFieldStyle.Render(strings.Join(s.notes.visible(), "\n")))
+	position := fmt.Sprintf("line %d/%d, col %d", s.notes.row+1, len(s.notes.lines), s.notes.col+1)
+	content = append(content, hintStyle.Render(position))
+
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("ctrl+s"), descStyle.Render("save notes")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("new line")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("arrows"), descStyle.Render("move cursor")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("pgup/pgdn"), descStyle.Render("scroll")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("discard changes")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderConfirmView asks a yes/no question about the selected todo.
+func (s State) renderConfirmView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(60).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	content = append(content, titleStyle.Render(s.confirmPrompt))
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("y"), descStyle.Render("yes")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("n"), descStyle.Render("no")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("cancel")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderBoard lays the active todos out as kanban columns side by side,
+// splitting the window width evenly between them. A column holding more
+// cards than its WIP limit gets a warning in its header.
+func (s State) renderBoard() string {
+	cards := boardCards(s.todos, s.columns)
+	width := max((s.windowWidth-2)/len(s.columns)-2, 12)
+	height := 0
+	for _, column := range cards {
+		height = max(height, len(column))
+	}
+	selected, _ := s.boardPosition(cards)
+	lineStyle := lipgloss.NewStyle().MaxWidth(width)
+
+	var boxes []string
+	for c, column := range s.columns {
+		count := 0
+		if c < len(s.columnCounts) {
+			count = s.columnCounts[c]
+		}
+		header := columnHeader(column, count)
+		if overLimit(column, count) {
+			header = boardOverLimitStyle.Render(header + " ⚠ over limit")
+		} else {
+			header = boardHeaderStyle.Render(header)
+		}
+		lines := []string{lineStyle.Render(header), ""}
+		for _, i := range cards[c] {
+			todo := s.todos[i]
+			cursor := cursorStyle.Render(" ")
+			content := itemStyle.Render(s.renderContent(todo, itemStyle))
+			if i == s.cursor {
+				cursor = cursorStyle.Render("▶︎")
+				content = selectedItemStyle.Render(s.renderContent(todo, selectedItemStyle))
+			}
+			if len(s.blockers[todo.ID]) > 0 {
+				content = blockedItemStyle.Render(todo.Content)
+			}
+			card := cursor + s.renderPriority(Priority(todo.Priority)) + " " + content + s.renderStatus(todo) + s.renderTracked(todo)
+			lines = append(lines, lineStyle.Render(card))
+		}
+		border := gray
+		if c == selected && len(s.todos) > 0 {
+			border = magenta
+		}
+		box := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(border).
+			Padding(0, 1).
+			Width(width).
+			Height(height + 2).
+			Render(strings.Join(lines, "\n"))
+		boxes = append(boxes, box)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+}
+
+// columnHeader names a board column with its card count and any WIP limit.
+func columnHeader(column orm.BoardColumn, count int) string {
+	header := fmt.Sprintf("%s %d", column.Name, count)
+	if column.WipLimit > 0 {
+		header += fmt.Sprintf("/%d", column.WipLimit)
+	}
+	return header
+}
+
+// renderLinkView draws the picker for choosing a blocker to link or unlink,
+// showing the typed filter above the matching todos.
+func (s State) renderLinkView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(60).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	inputFieldStyle := lipgloss.NewStyle().
+		Foreground(gray).
+		Padding(0, 2).
+		Width(50).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(yellow)
+	listStyle := lipgloss.NewStyle().
+		Width(50).
+		MarginBottom(1)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	verb, action := "blocked by", "link"
+	if s.unlinking {
+		verb, action = "stop waiting on", "unlink"
+	}
+	title := verb + "..."
+	if s.editingTodo != nil {
+		title = "\"" + s.editingTodo.Content + "\" " + title
+	}
+	content = append(content, titleStyle.Render(title))
+	content = append(content, inputFieldStyle.Render(s.editingText+"█"))
+
+	matches := s.linkMatches()
+	var lines []string
+	if len(matches) == 0 {
+		lines = append(lines, emptyStyle.Render("no matching todos"))
+	}
+	top := max(0, s.pickCursor-noteEditorHeight+1)
+	for i := top; i < min(top+noteEditorHeight, len(matches)); i++ {
+		todo := matches[i]
+		cursor := cursorStyle.Render(" ")
+		if i == s.pickCursor {
+			cursor = cursorStyle.Render("▶︎")
+		}
+		lines = append(lines, cursor+itemStyle.Render(fmt.Sprintf("#%d %s", todo.ID, todo.Content)))
+	}
+	content = append(content, listStyle.Render(strings.Join(lines, "\n")))
+
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("type"), descStyle.Render("filter by content or #id")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑ ↓"), descStyle.Render("choose todo")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render(action)))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("cancel")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderHistoryView lists the selected todo's events, newest first, in a
+// scrolling window around the cursor.
+func (s State) renderHistoryView() string {
+	formBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(1, 2).
+		Width(80).
+		Align(lipgloss.Center)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(magenta).
+		MarginBottom(1).
+		Align(lipgloss.Center)
+	listStyle := lipgloss.NewStyle().
+		Width(70).
+		MarginBottom(1)
+	keymapBoxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(magenta).
+		Padding(0, 2).
+		MarginRight(1)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(yellow).
+		Width(10)
+	descStyle := lipgloss.NewStyle().
+		Foreground(lightGray)
+
+	var content []string
+	title := "history"
+	if s.editingTodo != nil {
+		title = "history of \"" + s.editingTodo.Content + "\""
+	}
+	content = append(content, titleStyle.Render(title))
+
+	var lines []string
+	if len(s.history) == 0 {
+		lines = append(lines, emptyStyle.Render("no history recorded"))
+	}
+	top := max(0, s.historyCursor-noteEditorHeight+1)
+	for i := top; i < min(top+noteEditorHeight, len(s.history)); i++ {
+		e := s.history[i]
+		cursor := cursorStyle.Render(" ")
+		if i == s.historyCursor {
+			cursor = cursorStyle.Render("▶︎")
+		}
+		when := dueLaterStyle.Render(e.CreatedAt.Local().Format("Jan 2 15:04"))
+		lines = append(lines, cursor+when+" "+itemStyle.Render(describeEvent(e)))
+	}
+	content = append(content, listStyle.Render(strings.Join(lines, "\n")))
+
+	var keymaps []string
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑/k ↓/j"), descStyle.Render("choose event")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("enter"), descStyle.Render("restore this content")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("close")))
+	content = append(content, keymapBoxStyle.Render(strings.Join(keymaps, "\n")))
+
+	form := formBoxStyle.Render(strings.Join(content, "\n"))
+	if s.windowWidth > 0 && s.windowHeight > 0 {
+		availableHeight := s.windowHeight - len(asciiArt) - 4
+		form = lipgloss.Place(
+			s.windowWidth,
+			availableHeight,
+			lipgloss.Center,
+			lipgloss.Center,
+			form,
+		)
+	}
+	return form
+}
+
+// renderTreePrefix indents a todo by its depth in the subtask tree and marks
+// whether it is expanded, collapsed, or a leaf subtask.
+func (s State) renderTreePrefix(todo orm.Todo) string {
+	node := s.tree[todo.ID]
+	prefix := strings.Repeat("  ", node.depth)
+	switch {
+	case node.hasChildren && s.collapsed[todo.ID]:
+		prefix += "▸ "
+	case node.hasChildren:
+		prefix += "▾ "
+	case node.depth > 0:
+		prefix += "· "
+	default:
+		return ""
+	}
+	return treeStyle.Render(prefix)
+}
+
+// renderProgress shows how many of a todo's direct subtasks are done.
+func (s State) renderProgress(todo orm.Todo) string {
+	p, ok := s.progress[todo.ID]
+	if !ok {
+		return ""
+	}
+	return progressStyle.Render(fmt.Sprintf("%d/%d done", p.done, p.total))
+}
+
+// renderContent returns a todo's content, with the words matching the
+// search picked out when it is a hit. style is the row's own style, used for
+// the text between matches.
+func (s State) renderContent(todo orm.Todo, style lipgloss.Style) string {
+	if _, ok := s.searchHits[todo.ID]; !ok || !s.searchActive() {
+		return todo.Content
+	}
+	return highlightMatches(todo.Content, searchTerms(s.search), style.UnsetMarginRight())
+}
+
+// renderSearchPrompt shows the query being typed and how many todos match.
+func (s State) renderSearchPrompt() string {
+	prompt := "/" + s.search + "▌"
+	if s.search != "" {
+		prompt += fmt.Sprintf("  %d match(es) · enter keep · esc clear", len(s.searchHits))
+	}
+	return searchPromptStyle.Render(prompt)
+}
+
+// renderTracked shows a todo's total tracked time, ticking live while its
+// timer runs.
+func (s State) renderTracked(todo orm.Todo) string {
+	total := s.tracked[todo.ID]
+	if s.timer != nil && s.timer.TodoID == todo.ID {
+		return timerRunningStyle.Render("⏱ " + formatClock(total+elapsed(*s.timer, s.now)))
+	}
+	if total == 0 {
+		return ""
+	}
+	return trackedStyle.Render("⏱ " + formatTracked(total))
+}
+
 func (s State) renderPriority(priority Priority) string {
 	switch priority {
 	case P0:
@@ -413,6 +1376,66 @@ func (s State) renderPriority(priority Priority) string {
 	}
 }
 
+func (s State) renderTags(todo orm.Todo) string {
+	var chips string
+	for _, tag := range s.todoTags[todo.ID] {
+		chips += renderTagChip(tag)
+	}
+	return chips
+}
+
+func renderTagChip(tag string) string {
+	h := fnv.New32a()
+	h.Write([]byte(tag))
+	color := tagColors[h.Sum32()%uint32(len(tagColors))]
+	return tagChipStyle.Background(color).Render("#" + tag)
+}
+
+// renderDue returns the due date chip shown after a todo's content, coloured
+// by how close the deadline is.
+func (s State) renderDue(todo orm.Todo) string {
+	if !todo.DueAt.Valid {
+		return ""
+	}
+	now := time.Now()
+	label := "due " + formatDue(todo.DueAt.Time, now)
+	if todo.Completed {
+		return dueLaterStyle.Render(label)
+	}
+	switch dueStatus(todo.DueAt, now) {
+	case Overdue:
+		return dueOverdueStyle.Render("overdue: " + formatDue(todo.DueAt.Time, now))
+	case DueToday:
+		return dueTodayStyle.Render(label)
+	default:
+		return dueLaterStyle.Render(label)
+	}
+}
+
+// renderStatus badges todos that are in progress or blocked. Plain open and
+// done todos need no badge.
+func (s State) renderStatus(todo orm.Todo) string {
+	switch Status(todo.Status) {
+	case StatusDoing:
+		return statusDoingStyle.Render("▸ doing")
+	case StatusBlocked:
+		return statusBlockedStyle.Render("⊘ blocked")
+	}
+	return ""
+}
+
+// renderRecurrence marks a repeating todo with its rule.
+func (s State) renderRecurrence(todo orm.Todo) string {
+	if todo.Recurrence == "" {
+		return ""
+	}
+	r, err := ParseRecurrence(todo.Recurrence)
+	if err != nil {
+		return recurrenceStyle.Render("↻")
+	}
+	return recurrenceStyle.Render("↻ " + r.Describe())
+}
+
 func (s State) renderHelp() string {
 	var keymaps string
 	if s.showHelp {
@@ -457,16 +1480,83 @@ func (s State) renderKeymaps() string {
 	keymaps = append(keymaps, titleStyle.Render("? keymaps"))
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↑/k"), descStyle.Render("move up")))
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("↓/j"), descStyle.Render("move down")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("pgup/pgdn"), descStyle.Render("page up / down")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("gg / G"), descStyle.Render("jump to top / bottom")))
+	if s.boardActive() {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("←/h →/l"), descStyle.Render("previous / next column")))
+	} else {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("←/h →/l"), descStyle.Render("collapse / expand")))
+	}
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("tab"), descStyle.Render("cycle tabs")))
-	if s.viewMode == ActiveView {
-		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("n"), descStyle.Render("new todo")))
+	if s.viewMode != TrashView {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("/"), descStyle.Render("search")))
+	}
+	if s.searchActive() {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("n / N"), descStyle.Render("next / previous match")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("esc"), descStyle.Render("clear search")))
+	}
+	if s.listingActive() {
+		if !s.searchActive() {
+			keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("n"), descStyle.Render("new todo")))
+		}
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("a"), descStyle.Render("add subtask")))
 		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("e"), descStyle.Render("edit todo")))
 		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("space"), descStyle.Render("mark done")))
 		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("p"), descStyle.Render("cycle priority")))
-	} else {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("w / b"), descStyle.Render("doing / blocked")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("f"), descStyle.Render("filter by status")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("v"), descStyle.Render("toggle board")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("C"), descStyle.Render("manage board columns")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("L / U"), descStyle.Render("link / unlink blocker")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("B"), descStyle.Render("hide blocked")))
+		if s.boardActive() {
+			keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("[ / ]"), descStyle.Render("shift card left / right")))
+		}
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("D"), descStyle.Render("set due date")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("r"), descStyle.Render("set recurrence")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("s / O"), descStyle.Render("cycle / reverse sort")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("K / J"), descStyle.Render("move up / down")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("m"), descStyle.Render("move to project")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("> / <"), descStyle.Render("indent / outdent")))
+	} else if s.viewMode == CompletedView {
 		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("space"), descStyle.Render("mark not done")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("s / O"), descStyle.Render("cycle / reverse sort")))
+	}
+	if s.viewMode == TrashView {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("r"), descStyle.Render("restore todo")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("d"), descStyle.Render("delete forever")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("E"), descStyle.Render("empty trash")))
+	} else {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("o"), descStyle.Render("edit notes")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("t"), descStyle.Render("edit tags")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("S"), descStyle.Render("start / stop timer")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("d"), descStyle.Render("move to trash")))
+	}
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("u / ctrl+r"), descStyle.Render("undo / redo")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("i"), descStyle.Render("toggle details")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("H"), descStyle.Render("show history")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("T"), descStyle.Render("filter by tags")))
+	if s.viewMode != TrashView {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("F"), descStyle.Render("filter query")))
+	}
+	if s.filter.Active() {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("c"), descStyle.Render("clear filter")))
+	}
+	if !s.searchActive() {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("N"), descStyle.Render("new project")))
+	}
+	if s.viewMode == ProjectView {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("R"), descStyle.Render("rename project")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("X"), descStyle.Render("delete project")))
+	}
+	if s.filter.Active() {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("V"), descStyle.Render("save filter as view")))
+	}
+	if s.viewMode == SavedView {
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("R"), descStyle.Render("rename view")))
+		keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("X"), descStyle.Render("delete view")))
 	}
-	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("d"), descStyle.Render("delete todo")))
+	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("P"), descStyle.Render("switch profile")))
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("?"), descStyle.Render("toggle help")))
 	keymaps = append(keymaps, lipgloss.JoinHorizontal(lipgloss.Left, keyStyle.Render("q / ctrl+c"), descStyle.Render("quit")))
 	content := strings.Join(keymaps, "\n")
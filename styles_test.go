@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFitLabels(t *testing.T) {
+	labels := []string{"tags: #home", "filter: priority:P0,P1 text:deploy", "sort: priority asc"}
+	profile := "profile: work"
+	for width := 0; width <= 90; width++ {
+		got := fitLabels(labels, profile, width)
+		if w := lipgloss.Width(got); w > width {
+			t.Errorf("fitLabels at %d is %d wide: %q", width, w, got)
+		}
+		if width >= len(profile) && !strings.HasSuffix(got, profile) {
+			t.Errorf("fitLabels at %d = %q, want it to end with the profile", width, got)
+		}
+	}
+	if got, want := fitLabels(labels, profile, 90), strings.Join(append(labels, profile), "  "); got != want {
+		t.Errorf("fitLabels with room for everything = %q, want %q", got, want)
+	}
+	if got, want := fitLabels(labels, "", 40), "tags: #home  filter: priority:P0,P1 text"; got != want {
+		t.Errorf("fitLabels without a profile = %q, want %q", got, want)
+	}
+}
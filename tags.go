@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_./-]*$`)
+
+// NormalizeTag lowercases a tag and strips a leading '#', so "#Frontend"
+// and "frontend" name the same tag.
+func NormalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if !tagNamePattern.MatchString(tag) {
+		return "", fmt.Errorf("invalid tag %q (use letters, digits, and _ . / -)", name)
+	}
+	return tag, nil
+}
+
+// ParseTags normalizes a whitespace or comma separated list of tags,
+// dropping duplicates while keeping the original order.
+func ParseTags(input string) ([]string, error) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	tags := []string{}
+	for _, f := range fields {
+		tag, err := NormalizeTag(f)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// EditTags attaches and detaches tags on a todo in a single transaction,
+// creating new tags as needed and dropping any left without todos.
+func (d *Database) EditTags(ctx context.Context, todoID int, add, remove []string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	for _, name := range add {
+		tag, err := q.UpsertTag(ctx, name)
+		if err != nil {
+			return err
+		}
+		err = q.AttachTag(ctx, orm.AttachTagParams{TodoID: todoID, TagID: tag.ID})
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range remove {
+		err := q.DetachTag(ctx, orm.DetachTagParams{TodoID: todoID, Name: name})
+		if err != nil {
+			return err
+		}
+	}
+	if err := q.DeleteUnusedTags(ctx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTags replaces a todo's tags with exactly the given set.
+func (d *Database) SetTags(ctx context.Context, todoID int, tags []string) error {
+	current, err := d.Queries.GetTodoTags(ctx, todoID)
+	if err != nil {
+		return err
+	}
+	var remove []string
+	for _, tag := range current {
+		if !slices.Contains(tags, tag.Name) {
+			remove = append(remove, tag.Name)
+		}
+	}
+	return d.EditTags(ctx, todoID, tags, remove)
+}
+
+// TodoTags returns the tag names of the given todos, keyed by todo id.
+// Untagged todos are left out.
+func (d *Database) TodoTags(ctx context.Context, ids []int) (map[int][]string, error) {
+	rows, err := d.Queries.GetTagsForTodos(ctx, idList(ids))
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[int][]string)
+	for _, row := range rows {
+		tags[row.TodoID] = append(tags[row.TodoID], row.Name)
+	}
+	return tags, nil
+}
+
+// filterByTags keeps the todos carrying at least one of the wanted tags.
+// An empty filter keeps everything.
+func filterByTags(todos []orm.Todo, tags map[int][]string, want []string) []orm.Todo {
+	if len(want) == 0 {
+		return todos
+	}
+	filtered := []orm.Todo{}
+	for _, todo := range todos {
+		for _, tag := range tags[todo.ID] {
+			if slices.Contains(want, tag) {
+				filtered = append(filtered, todo)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StartTimer starts a timer on a todo. Only one timer runs at a time, so any
+// other running timer is stopped first; if id's timer is already running it
+// is left alone.
+func (d *Database) StartTimer(ctx context.Context, id int) (orm.TimeEntry, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return orm.TimeEntry{}, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	running, err := q.GetRunningTimeEntry(ctx)
+	if err == nil && running.TodoID == id {
+		return running, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return orm.TimeEntry{}, err
+	}
+	if err := q.StopRunningTimeEntries(ctx, sql.NullTime{Time: now, Valid: true}); err != nil {
+		return orm.TimeEntry{}, err
+	}
+	entry, err := q.StartTimeEntry(ctx, orm.StartTimeEntryParams{TodoID: id, StartedAt: now})
+	if err != nil {
+		return orm.TimeEntry{}, err
+	}
+	return entry, tx.Commit()
+}
+
+// StopTimer stops the running timer, returning it as stopped, or nil if no
+// timer was running.
+func (d *Database) StopTimer(ctx context.Context) (*orm.TimeEntry, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	running, err := q.GetRunningTimeEntry(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	running.StoppedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := q.StopRunningTimeEntries(ctx, running.StoppedAt); err != nil {
+		return nil, err
+	}
+	return &running, tx.Commit()
+}
+
+// resumeTimer starts the timer on id again after undoing the completion that
+// stopped it, unless a timer has been started on something else since.
+func (d *Database) resumeTimer(ctx context.Context, id int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	if _, err := q.GetRunningTimeEntry(ctx); !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if _, err := q.StartTimeEntry(ctx, orm.StartTimeEntryParams{TodoID: id, StartedAt: time.Now()}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RunningTimer returns the timer that is running, or nil if none is.
+func (d *Database) RunningTimer(ctx context.Context) (*orm.TimeEntry, error) {
+	entry, err := d.Queries.GetRunningTimeEntry(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// TrackedTime totals the stopped timers of each of the given todos. A
+// running timer isn't included; add its elapsed time to show it live.
+func (d *Database) TrackedTime(ctx context.Context, ids []int) (map[int]time.Duration, error) {
+	rows, err := d.Queries.GetTrackedSeconds(ctx, idList(ids))
+	if err != nil {
+		return nil, err
+	}
+	tracked := make(map[int]time.Duration, len(rows))
+	for _, row := range rows {
+		tracked[row.TodoID] = time.Duration(row.Seconds) * time.Second
+	}
+	return tracked, nil
+}
+
+// stopTimer stops id's timer if it is running. Completing or trashing a todo
+// stops its timer so time isn't billed to work that's finished.
+func stopTimer(ctx context.Context, q *orm.Queries, id int, now time.Time) error {
+	return q.StopTodoTimeEntries(ctx, orm.StopTodoTimeEntriesParams{
+		TodoID:    id,
+		StoppedAt: sql.NullTime{Time: now, Valid: true},
+	})
+}
+
+// elapsed is how long a timer has run, up to now if it hasn't stopped.
+func elapsed(entry orm.TimeEntry, now time.Time) time.Duration {
+	if entry.StoppedAt.Valid {
+		return entry.StoppedAt.Time.Sub(entry.StartedAt)
+	}
+	return now.Sub(entry.StartedAt)
+}
+
+// formatTracked renders a total like "2h05m" or "12m". Anything under a
+// minute shows as "<1m" so a fresh timer doesn't read as no time at all.
+func formatTracked(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatClock renders a running timer as h:mm:ss.
+func formatClock(d time.Duration) string {
+	s := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// ReportBy is how the time report groups tracked time.
+type ReportBy string
+
+const (
+	ReportByDay ReportBy = "day"
+	ReportByTag ReportBy = "tag"
+)
+
+// untaggedKey groups time spent on todos without tags in a tag report.
+const untaggedKey = "(untagged)"
+
+// ParseReportBy validates a report grouping.
+func ParseReportBy(s string) (ReportBy, error) {
+	switch by := ReportBy(s); by {
+	case ReportByDay, ReportByTag:
+		return by, nil
+	}
+	return ReportByDay, fmt.Errorf("invalid grouping %q (want day or tag)", s)
+}
+
+// reportRow is one line of a time report.
+type reportRow struct {
+	key   string
+	total time.Duration
+}
+
+// TimeReport sums the time tracked between since and now, by local day or by
+// tag. Timers that straddle midnight are split between the days they cover,
+// and time on a todo with several tags counts toward each of them. Days are
+// listed in order; tags from most to least time.
+func (d *Database) TimeReport(ctx context.Context, by ReportBy, since, now time.Time) ([]reportRow, error) {
+	entries, err := d.Queries.GetTimeEntriesSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(entries))
+	for i, e := range entries {
+		ids[i] = e.TodoID
+	}
+	tags, err := d.TodoTags(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	totals := map[string]time.Duration{}
+	for _, e := range entries {
+		start := e.StartedAt.Local()
+		if start.Before(since) {
+			start = since
+		}
+		end := now
+		if e.StoppedAt.Valid {
+			end = e.StoppedAt.Time.Local()
+		}
+		if !end.After(start) {
+			continue
+		}
+		switch by {
+		case ReportByDay:
+			for day := startOfDay(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+				from, to := start, end
+				if day.After(from) {
+					from = day
+				}
+				if next := day.AddDate(0, 0, 1); next.Before(to) {
+					to = next
+				}
+				totals[day.Format("2006-01-02")] += to.Sub(from)
+			}
+		case ReportByTag:
+			keys := tags[e.TodoID]
+			if len(keys) == 0 {
+				keys = []string{untaggedKey}
+			}
+			for _, key := range keys {
+				totals[key] += end.Sub(start)
+			}
+		}
+	}
+	rows := []reportRow{}
+	for _, key := range slices.Sorted(maps.Keys(totals)) {
+		rows = append(rows, reportRow{key: key, total: totals[key]})
+	}
+	if by == ReportByTag {
+		slices.SortStableFunc(rows, func(a, b reportRow) int {
+			return cmp.Compare(b.total, a.total)
+		})
+	}
+	return rows, nil
+}
+
+type timerTickMsg time.Time
+
+// tickTimer schedules the next redraw of the running timer.
+func tickTimer() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return timerTickMsg(t)
+	})
+}
+
+// toggleTimer stops todo's timer if it's the one running, and otherwise
+// starts it.
+func (s State) toggleTimer(todo orm.Todo) tea.Cmd {
+	running := s.timer != nil && s.timer.TodoID == todo.ID
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if running {
+			stopped, err := s.database.StopTimer(ctx)
+			if err != nil {
+				return tea.Msg(fmt.Sprintf("Error stopping timer: %v", err))
+			}
+			return timerToggledMsg{todo: todo, stopped: stopped}
+		}
+		if _, err := s.database.StartTimer(ctx, todo.ID); err != nil {
+			return tea.Msg(fmt.Sprintf("Error starting timer: %v", err))
+		}
+		return timerToggledMsg{todo: todo, started: true}
+	})
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// DefaultTrashDays is how long deleted todos stay in the trash before they
+// are purged for good.
+const DefaultTrashDays = 30
+
+// ResolveTrashDays picks the trash retention from the flag value, falling
+// back to GODOIT_TRASH_DAYS and then DefaultTrashDays. A negative flag value
+// means the flag was not given.
+func ResolveTrashDays(flagValue int) (int, error) {
+	if flagValue >= 0 {
+		return flagValue, nil
+	}
+	env := os.Getenv("GODOIT_TRASH_DAYS")
+	if env == "" {
+		return DefaultTrashDays, nil
+	}
+	days, err := strconv.Atoi(env)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid GODOIT_TRASH_DAYS %q (want a number of days, 0 to keep forever)", env)
+	}
+	return days, nil
+}
+
+// Trash moves a todo and its subtasks to the trash, stopping their timers and
+// releasing the todos they block.
+func (d *Database) Trash(ctx context.Context, id int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	descendants, err := q.GetTodoDescendants(ctx, nullID(id))
+	if err != nil {
+		return err
+	}
+	if err := logEvent(ctx, q, id, EventDelete, "", "", now); err != nil {
+		return err
+	}
+	if err := stopTimer(ctx, q, id, now); err != nil {
+		return err
+	}
+	for _, child := range descendants {
+		if err := logEvent(ctx, q, child.ID, EventDelete, "", "", now); err != nil {
+			return err
+		}
+		if err := stopTimer(ctx, q, child.ID, now); err != nil {
+			return err
+		}
+	}
+	err = q.TrashTodo(ctx, orm.TrashTodoParams{
+		ID:        id,
+		DeletedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	// A trashed todo no longer holds anything up.
+	if err := unblockDependents(ctx, q, id, now); err != nil {
+		return err
+	}
+	for _, child := range descendants {
+		if err := unblockDependents(ctx, q, child.ID, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Restore brings a todo and its subtasks back from the trash. A todo whose
+// parent is still trashed becomes a top-level todo, so purging the parent
+// later can't take it along. Blocked statuses are worked out again on both
+// sides of each restored todo's links.
+func (d *Database) Restore(ctx context.Context, id int) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	if err := q.RestoreTodo(ctx, id); err != nil {
+		return err
+	}
+	if err := q.DetachFromTrashedParent(ctx, id); err != nil {
+		return err
+	}
+	now := time.Now()
+	descendants, err := q.GetTodoDescendants(ctx, nullID(id))
+	if err != nil {
+		return err
+	}
+	restored := []int{id}
+	for _, child := range descendants {
+		restored = append(restored, child.ID)
+	}
+	for _, rid := range restored {
+		if err := logEvent(ctx, q, rid, EventRestore, "", "", now); err != nil {
+			return err
+		}
+	}
+	// Restored todos wait on their open blockers again, and hold up the
+	// todos waiting on them. Blockers trashed in the meantime no longer count.
+	for _, rid := range restored {
+		if err := releaseIfUnblocked(ctx, q, rid, now); err != nil {
+			return err
+		}
+		if err := blockIfWaiting(ctx, q, rid, now); err != nil {
+			return err
+		}
+		todo, err := q.GetTodo(ctx, rid)
+		if err != nil {
+			return err
+		}
+		if !todo.Completed {
+			if err := blockDependents(ctx, q, rid, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// PurgeExpiredTrash permanently deletes todos that have been in the trash for
+// longer than days, returning how many were removed. Like EmptyTrash, it
+// ends each removed todo's history with a purge.
+func (d *Database) PurgeExpiredTrash(ctx context.Context, days int, now time.Time) (int64, error) {
+	if days <= 0 {
+		return 0, nil
+	}
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	cutoff := now.AddDate(0, 0, -days)
+	expired, err := q.GetTrashedBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range expired {
+		if err := logEvent(ctx, q, id, EventPurge, "", "", now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := q.PurgeTrashedBefore(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
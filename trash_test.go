@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// TestPurgeExpiredTrashAcrossZones trashes todos with timestamps written in
+// other zones than the cutoff's, where comparing the stored text would get
+// the order wrong.
+func TestPurgeExpiredTrashAcrossZones(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	east := time.FixedZone("east", 5*60*60)
+	west := time.FixedZone("west", -5*60*60)
+	now := time.Date(2026, time.March, 31, 7, 0, 0, 0, time.UTC)
+	trashed := map[string]time.Time{
+		// 05:00 UTC on the 1st, two hours before the cutoff, though its
+		// text reads later.
+		"expired": time.Date(2026, time.March, 1, 10, 0, 0, 0, east),
+		// 09:00 UTC on the 1st, two hours after the cutoff, though its
+		// text reads earlier.
+		"kept": time.Date(2026, time.March, 1, 4, 0, 0, 0, west),
+	}
+	ids := map[string]int{}
+	for content, at := range trashed {
+		todo, err := d.Queries.CreateTodo(ctx, orm.CreateTodoParams{Content: content, Priority: string(P2), CreatedAt: at, UpdatedAt: at})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Queries.TrashTodo(ctx, orm.TrashTodoParams{ID: todo.ID, DeletedAt: sql.NullTime{Time: at, Valid: true}}); err != nil {
+			t.Fatal(err)
+		}
+		ids[content] = todo.ID
+	}
+	n, err := d.PurgeExpiredTrash(ctx, 30, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("purged %d todos, want 1", n)
+	}
+	if _, err := d.Queries.GetTodo(ctx, ids["expired"]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expired todo is still there (err %v)", err)
+	}
+	if _, err := d.Queries.GetTodo(ctx, ids["kept"]); err != nil {
+		t.Errorf("kept todo: %v", err)
+	}
+	// The purge shows in the history of what it removed, and only there.
+	for content, want := range map[string]bool{"expired": true, "kept": false} {
+		events, err := d.Queries.GetTodoEvents(ctx, ids[content])
+		if err != nil {
+			t.Fatal(err)
+		}
+		purged := len(events) > 0 && events[0].Kind == EventPurge
+		if purged != want {
+			t.Errorf("%s todo: history ends in a purge = %t, want %t", content, purged, want)
+		}
+	}
+}
+
+// TestNewDatabasePurgesWithItsRetention checks that opening a database
+// purges with the retention it is given rather than any shared setting.
+func TestNewDatabasePurgesWithItsRetention(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "todos.db")
+	open := func(days int) *Database {
+		t.Helper()
+		d, err := NewDatabase(path, days)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { d.Close() })
+		return d
+	}
+	d := open(0)
+	todo := createTestTodos(t, d, "old")[0]
+	err := d.Queries.TrashTodo(ctx, orm.TrashTodoParams{
+		ID:        todo.ID,
+		DeletedAt: sql.NullTime{Time: time.Now().AddDate(0, 0, -10), Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, days := range []int{0, 30, 7} {
+		d := open(days)
+		_, err := d.Queries.GetTodo(ctx, todo.ID)
+		if purged := errors.Is(err, sql.ErrNoRows); purged != (days == 7) {
+			t.Errorf("opening with %d days: purged = %v, err = %v", days, purged, err)
+		}
+		if d.TrashDays != days {
+			t.Errorf("TrashDays = %d, want %d", d.TrashDays, days)
+		}
+	}
+}
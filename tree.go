@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// treeNode is a todo's place in the visible subtask tree.
+type treeNode struct {
+	depth       int
+	hasChildren bool
+}
+
+// subtaskProgress counts a todo's direct subtasks and how many are done.
+type subtaskProgress struct {
+	total int
+	done  int
+}
+
+// flattenTree orders todos as a depth-first tree. Siblings keep their order
+// from the input, and a todo whose parent isn't in the input is treated as a
+// root so filtered or completed parents never hide their subtasks. Children
+// of collapsed todos are left out.
+func flattenTree(todos []orm.Todo, collapsed map[int]bool) ([]orm.Todo, map[int]treeNode) {
+	present := make(map[int]bool, len(todos))
+	for _, todo := range todos {
+		present[todo.ID] = true
+	}
+	var roots []orm.Todo
+	children := make(map[int][]orm.Todo)
+	for _, todo := range todos {
+		if todo.ParentID.Valid && present[int(todo.ParentID.Int64)] {
+			parent := int(todo.ParentID.Int64)
+			children[parent] = append(children[parent], todo)
+		} else {
+			roots = append(roots, todo)
+		}
+	}
+	visible := make([]orm.Todo, 0, len(todos))
+	nodes := make(map[int]treeNode, len(todos))
+	visited := make(map[int]bool, len(todos))
+	var walk func(todo orm.Todo, depth int)
+	walk = func(todo orm.Todo, depth int) {
+		if visited[todo.ID] {
+			return
+		}
+		visited[todo.ID] = true
+		visible = append(visible, todo)
+		nodes[todo.ID] = treeNode{depth: depth, hasChildren: len(children[todo.ID]) > 0}
+		if collapsed[todo.ID] {
+			return
+		}
+		for _, child := range children[todo.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return visible, nodes
+}
+
+// SubtaskProgress returns, for each of the given todos that has subtasks, how
+// many of its direct subtasks exist and how many of those are done.
+func (d *Database) SubtaskProgress(ctx context.Context, ids []int) (map[int]subtaskProgress, error) {
+	rows, err := d.Queries.GetSubtaskProgress(ctx, idList(ids))
+	if err != nil {
+		return nil, err
+	}
+	progress := make(map[int]subtaskProgress, len(rows))
+	for _, row := range rows {
+		progress[int(row.ParentID.Int64)] = subtaskProgress{total: int(row.Total), done: int(row.Done)}
+	}
+	return progress, nil
+}
+
+// CompleteWithSubtasks marks a todo and all of its open descendants done in
+// a single transaction, scheduling the todo's next occurrence if it recurs.
+// It returns the descendants it completed, as they were before, and the next
+// occurrence if one was created.
+func (d *Database) CompleteWithSubtasks(ctx context.Context, id int) ([]orm.Todo, *orm.Todo, error) {
+	return d.completeWithSubtasks(ctx, id, true)
+}
+
+// completeWithSubtasks is CompleteWithSubtasks with control over spawning the
+// next occurrence, for undo to replay.
+func (d *Database) completeWithSubtasks(ctx context.Context, id int, spawn bool) ([]orm.Todo, *orm.Todo, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	next, err := toggle(ctx, q, id, spawn, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	descendants, err := q.GetTodoDescendants(ctx, nullID(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	var opened []orm.Todo
+	for _, child := range descendants {
+		if !child.Completed && !child.DeletedAt.Valid {
+			opened = append(opened, child)
+		}
+	}
+	for _, child := range opened {
+		err := logEvent(ctx, q, child.ID, EventToggle, completionValue(false), completionValue(true), now)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	err = q.CompleteTodoDescendants(ctx, orm.CompleteTodoDescendantsParams{
+		ParentID:    nullID(id),
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, child := range opened {
+		if err := stopTimer(ctx, q, child.ID, now); err != nil {
+			return nil, nil, err
+		}
+		if err := unblockDependents(ctx, q, child.ID, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	return opened, next, tx.Commit()
+}
+
+// uncompleteWithSubtasks undoes CompleteWithSubtasks: todo and the subtasks
+// it completed go back to being open, each in the workflow state it had, and
+// whatever they block waits on them again. Anything reopened by hand since is
+// left as it is.
+func (d *Database) uncompleteWithSubtasks(ctx context.Context, todo orm.Todo, subtasks []orm.Todo) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := d.Queries.WithTx(tx)
+	now := time.Now()
+	for _, t := range append([]orm.Todo{todo}, subtasks...) {
+		if err := reopen(ctx, q, t, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// reopen puts a completed todo back to the open state it was in before,
+// given as it was then.
+func reopen(ctx context.Context, q *orm.Queries, was orm.Todo, now time.Time) error {
+	todo, err := q.GetTodo(ctx, was.ID)
+	if err != nil || !todo.Completed {
+		return err
+	}
+	if _, err := toggle(ctx, q, todo.ID, false, now); err != nil {
+		return err
+	}
+	if Status(was.Status) == StatusTodo || Status(was.Status) == StatusDone {
+		return nil
+	}
+	todo.Status = string(StatusTodo)
+	return setStatusLogged(ctx, q, todo, Status(was.Status), now)
+}
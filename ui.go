@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"maps"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +18,9 @@ type ViewMode int
 const (
 	ActiveView ViewMode = iota
 	CompletedView
+	TrashView
+	ProjectView
+	SavedView
 )
 
 type UIState int
@@ -23,24 +29,114 @@ const (
 	BrowsingState UIState = iota
 	EditingState
 	CreatingState
+	ProfileState
+	DueState
+	RecurrenceState
+	TagState
+	TagFilterState
+	ProjectCreateState
+	ProjectRenameState
+	MoveState
+	ConfirmState
+	NotesState
+	HistoryState
+	LinkState
+	SearchState
+	FilterState
+	ViewCreateState
+	ViewRenameState
+	ColumnsState
+	ColumnCreateState
+	ColumnRenameState
+	ColumnLimitState
 )
 
 type State struct {
-	database     *Database
-	todos        []orm.Todo
-	cursor       int
-	viewMode     ViewMode
-	uiState      UIState
-	editingTodo  *orm.Todo
-	editingText  string
-	message      string
-	windowWidth  int
-	windowHeight int
-	showHelp     bool
+	database      *Database
+	location      DBLocation
+	profiles      []string
+	profileCursor int
+	loaded        []orm.Todo
+	todos         []orm.Todo
+	tree          map[int]treeNode
+	collapsed     map[int]bool
+	progress      map[int]subtaskProgress
+	parentID      int
+	confirmPrompt string
+	confirmYes    tea.Cmd
+	confirmNo     tea.Cmd
+	notes         noteEditor
+	history       []orm.TodoEvent
+	historyCursor int
+	showDetail    bool
+	todoTags      map[int][]string
+	tagFilter     []string
+	statusFilter  Status
+	filter        Filter
+	projects      []orm.Project
+	views         []orm.SavedView
+	tabCounts     tabCounts
+	columns       []orm.BoardColumn
+	columnCounts  []int
+	showBoard     bool
+	columnCursor  int
+	blockers      map[int][]int
+	hideBlocked   bool
+	picks         []orm.Todo
+	pickCursor    int
+	unlinking     bool
+	search        string
+	searchHits    map[int]bool
+	seekHit       int
+	timer         *orm.TimeEntry
+	tracked       map[int]time.Duration
+	ticking       bool
+	now           time.Time
+	projectID     int
+	viewID        int
+	moveCursor    int
+	cursor        int
+	viewMode      ViewMode
+	uiState       UIState
+	editingTodo   *orm.Todo
+	editingText   string
+	message       string
+	windowWidth   int
+	windowHeight  int
+	showHelp      bool
+	sorts         map[string]Sort
+	page          todoPage
+	fetching      bool
+	offset        int
+	pendingG      bool
+	undoStack     []action
+	redoStack     []action
+	status        string
+	followID      int
 }
 
 type todoLoadedMsg struct {
-	todos []orm.Todo
+	todos     []orm.Todo
+	details   todoDetails
+	projects  []orm.Project
+	views     []orm.SavedView
+	tabCounts tabCounts
+	columns   []orm.BoardColumn
+	counts    []int
+	timer     *orm.TimeEntry
+	sorts     map[string]Sort
+	page      todoPage
+}
+
+// tabCounts are the numbers of todos shown on the tabs. They're read with
+// the todos, so drawing the tabs doesn't query the database. A view whose
+// query no longer parses has no count.
+type tabCounts struct {
+	active    int
+	completed int
+	trashed   int
+	projects  map[int]int
+	views     map[int]int
 }
 
 type todoCreatedMsg struct {
@@ -49,19 +145,84 @@ type todoCreatedMsg struct {
 
 type todoUpdatedMsg struct {
 	success bool
+	action  *action
+}
+
+type historyLoadedMsg struct {
+	todo   orm.Todo
+	events []orm.TodoEvent
+}
+
+type linkPickerMsg struct {
+	todo      orm.Todo
+	picks     []orm.Todo
+	unlinking bool
+}
+
+type linkPicksMsg struct {
+	query string
+	picks []orm.Todo
+}
+
+type searchResultMsg struct {
+	query string
+	hits  map[int]bool
+}
+
+type cardMovedMsg struct {
+	id      int
+	warning string
+}
+
+type timerToggledMsg struct {
+	todo    orm.Todo
+	started bool
+	stopped *orm.TimeEntry
+}
+
+type todoReorderedMsg struct {
+	id int
 }
 
 type todoDeletedMsg struct {
 	success bool
+	action  *action
 }
 
-func InitialState(database *Database) State {
+// projectChangedMsg reports a created, renamed, or deleted project along with
+// the project tab to show next (0 for the active tab).
+type projectChangedMsg struct {
+	projectID int
+}
+
+// viewChangedMsg reports a created, renamed, or deleted saved view along
+// with the view tab to show next (0 for the active tab).
+type viewChangedMsg struct {
+	viewID int
+}
+
+// columnChangedMsg reports a created, renamed, limited, or deleted board
+// column along with the column to select in the column manager next.
+type columnChangedMsg struct {
+	cursor int
+}
+
+type sortChangedMsg struct{}
+
+type profileSwitchedMsg struct {
+	database *Database
+	location DBLocation
+}
+
+func InitialState(database *Database, location DBLocation) State {
 	return State{
-		database: database,
-		todos:    []orm.Todo{},
-		cursor:   0,
-		viewMode: ActiveView,
-		uiState:  BrowsingState,
+		database:  database,
+		location:  location,
+		todos:     []orm.Todo{},
+		collapsed: map[int]bool{},
+		cursor:    0,
+		viewMode:  ActiveView,
+		uiState:   BrowsingState,
 	}
 }
 
@@ -72,44 +233,331 @@ func (s State) Init() tea.Cmd {
 func (s State) loadTodos() tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		ctx := context.Background()
+		sorts, err := s.database.TabSorts(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading sort order: %v", err))
+		}
+		s.sorts = sorts
+		projects, err := s.database.Queries.ListProjects(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading projects: %v", err))
+		}
+		views, err := s.database.Queries.ListViews(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading views: %v", err))
+		}
+		tabCounts, err := s.database.countTabs(ctx, projects, views)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error counting todos: %v", err))
+		}
+		filter, err := s.listFilter(views)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error %v", err))
+		}
 		var todos []orm.Todo
-		var err error
-		if s.viewMode == ActiveView {
-			todos, err = s.database.Queries.GetActiveTodos(ctx)
+		var page todoPage
+		if s.viewMode == TrashView {
+			todos, err = s.database.Queries.GetTrashedTodos(ctx)
 		} else {
-			todos, err = s.database.Queries.GetCompletedTodos(ctx)
+			limit, now := s.pageLimit(), time.Now()
+			var subtasks []orm.Todo
+			todos, subtasks, err = s.database.ListTodoTrees(ctx, s.viewMode == CompletedView, filter, s.currentSort(), now, 0, limit)
+			page = newTodoPage(todos, limit)
+			page.at = now
+			todos = append(todos, subtasks...)
 		}
 		if err != nil {
 			return tea.Msg(fmt.Sprintf("Error loading todos: %v", err))
 		}
-		return todoLoadedMsg{todos: todos}
+		details, err := s.database.loadDetails(ctx, todos)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error %v", err))
+		}
+		if s.viewMode == TrashView {
+			// The trash isn't paged, and only the tag filter applies to it.
+			todos = filterByTags(todos, details.tags, s.tagFilter)
+		}
+		columns, err := s.database.Queries.ListColumns(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading columns: %v", err))
+		}
+		counts, err := s.database.ColumnCounts(ctx, columns, filter)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error counting cards: %v", err))
+		}
+		timer, err := s.database.RunningTimer(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading timer: %v", err))
+		}
+		return todoLoadedMsg{todos: todos, details: details, projects: projects, views: views, tabCounts: tabCounts, columns: columns, counts: counts, timer: timer, sorts: sorts, page: page}
 	})
 }
 
+// countTabs counts the todos of each tab.
+func (d *Database) countTabs(ctx context.Context, projects []orm.Project, views []orm.SavedView) (tabCounts, error) {
+	active, err := d.Queries.CountActiveTodos(ctx)
+	if err != nil {
+		return tabCounts{}, err
+	}
+	completed, err := d.Queries.CountCompletedTodos(ctx)
+	if err != nil {
+		return tabCounts{}, err
+	}
+	trashed, err := d.Queries.CountTrashedTodos(ctx)
+	if err != nil {
+		return tabCounts{}, err
+	}
+	counts := tabCounts{
+		active:    int(active),
+		completed: int(completed),
+		trashed:   int(trashed),
+		projects:  make(map[int]int, len(projects)),
+		views:     make(map[int]int, len(views)),
+	}
+	for _, p := range projects {
+		n, err := d.Queries.CountActiveProjectTodos(ctx, nullID(p.ID))
+		if err != nil {
+			return tabCounts{}, err
+		}
+		counts.projects[p.ID] = int(n)
+	}
+	now := time.Now()
+	for _, v := range views {
+		filter, err := ParseFilter(v.Query, now)
+		if err != nil {
+			continue
+		}
+		if counts.views[v.ID], err = d.CountView(ctx, filter); err != nil {
+			return tabCounts{}, err
+		}
+	}
+	return counts, nil
+}
+
+// listFilter is the filter for the todos the current tab shows, combining
+// its project or saved view with the tag, query, status and blocked filters.
+// It runs in the SQL that reads each page, so a small tab is read in a page
+// or two however many todos the database holds.
+func (s State) listFilter(views []orm.SavedView) (Filter, error) {
+	var f Filter
+	switch s.viewMode {
+	case ProjectView:
+		f = f.and("project_id = ?", s.projectID)
+	case SavedView:
+		view, err := viewFilter(views, s.viewID, time.Now())
+		if err != nil {
+			return Filter{}, fmt.Errorf("loading view: %w", err)
+		}
+		f = f.merge(view)
+	}
+	if len(s.tagFilter) > 0 {
+		args := make([]any, len(s.tagFilter))
+		for i, tag := range s.tagFilter {
+			args[i] = tag
+		}
+		f = f.and(taggedWith(len(args)), args...)
+	}
+	f = f.merge(s.filter)
+	if s.listingActive() {
+		if s.statusFilter != "" {
+			f = f.and("status = ?", string(s.statusFilter))
+		}
+		if s.hideBlocked {
+			f = f.and(unblocked)
+		}
+	}
+	return f, nil
+}
+
+// Update handles a message, then scrolls the list to keep the cursor in view
+// and fetches the next page once the cursor nears the end of those loaded.
 func (s State) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := s.update(msg)
+	next, ok := m.(State)
+	if !ok {
+		return m, cmd
+	}
+	next = next.scrollToCursor()
+	if more := next.loadMoreIfNeeded(); more != nil {
+		next.fetching = true
+		return next, tea.Batch(cmd, more)
+	}
+	return next, cmd
+}
+
+func (s State) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		s.windowWidth = msg.Width
 		s.windowHeight = msg.Height
 	case todoLoadedMsg:
-		s.todos = msg.todos
+		s.loaded = msg.todos
+		s.page = msg.page
+		s.fetching = false
+		s.todos, s.tree = flattenTree(s.loaded, s.collapsed)
+		if s.searchActive() {
+			s = s.revealHits()
+		}
+		s.todoTags = msg.details.tags
+		s.projects = msg.projects
+		s.views = msg.views
+		s.tabCounts = msg.tabCounts
+		s.progress = msg.details.progress
+		s.columns = msg.columns
+		s.columnCounts = msg.counts
+		s.columnCursor = max(0, min(s.columnCursor, len(s.columns)-1))
+		s.blockers = msg.details.blockers
+		s.timer = msg.timer
+		s.tracked = msg.details.tracked
+		s.sorts = msg.sorts
+		s.now = time.Now()
+		if s.followID != 0 {
+			for i, t := range s.todos {
+				if t.ID == s.followID {
+					s.cursor = i
+				}
+			}
+			s.followID = 0
+		}
+		if s.seekHit != 0 {
+			s = s.seekFirstHit(s.seekHit)
+			s.seekHit = 0
+		}
 		if s.cursor >= len(s.todos) && len(s.todos) > 0 {
 			s.cursor = len(s.todos) - 1
 		} else if len(s.todos) == 0 {
 			s.cursor = 0
 		}
 		s.message = ""
+		if s.timer != nil && !s.ticking {
+			s.ticking = true
+			return s, tickTimer()
+		}
+	case timerTickMsg:
+		if s.timer == nil {
+			s.ticking = false
+			return s, nil
+		}
+		s.now = time.Time(msg)
+		return s, tickTimer()
+	case timerToggledMsg:
+		if msg.started {
+			s.status = fmt.Sprintf("⏱ started timer on %q", msg.todo.Content)
+		} else if msg.stopped != nil {
+			s.status = fmt.Sprintf("⏱ stopped timer on %q after %s", msg.todo.Content, formatClock(elapsed(*msg.stopped, time.Now())))
+		}
+		return s, s.loadTodos()
 	case todoCreatedMsg:
 		s.uiState = BrowsingState
 		s.editingText = ""
+		s.parentID = 0
+		s = s.record(trashAction(fmt.Sprintf("create %q", msg.todo.Content), msg.todo.ID, false))
 		return s, s.loadTodos()
 	case todoUpdatedMsg:
+		s = s.record(msg.action)
 		s.uiState = BrowsingState
 		s.editingTodo = nil
 		s.editingText = ""
+		s.notes = noteEditor{}
+		s.picks = nil
 		return s, s.loadTodos()
 	case todoDeletedMsg:
+		s = s.record(msg.action)
+		return s, s.loadTodos()
+	case replayedMsg:
+		if msg.redo {
+			s.undoStack = pushAction(s.undoStack, msg.action)
+			s.status = "↺ redid: " + msg.action.label
+		} else {
+			s.redoStack = pushAction(s.redoStack, msg.action)
+			s.status = "↺ undid: " + msg.action.label
+		}
+		return s, s.loadTodos()
+	case historyLoadedMsg:
+		s.uiState = HistoryState
+		s.editingTodo = &msg.todo
+		s.history = msg.events
+		s.historyCursor = 0
+	case sortChangedMsg:
+		return s, s.loadTodos()
+	case todoPageMsg:
+		return s.appendPage(msg), nil
+	case todoReorderedMsg:
+		s.followID = msg.id
+		return s, s.loadTodos()
+	case linkPickerMsg:
+		s.uiState = LinkState
+		s.editingTodo = &msg.todo
+		s.editingText = ""
+		s.picks = msg.picks
+		s.pickCursor = 0
+		s.unlinking = msg.unlinking
+	case linkPicksMsg:
+		if s.uiState == LinkState && msg.query == s.editingText {
+			s.picks = msg.picks
+			s.pickCursor = min(s.pickCursor, max(0, len(s.linkMatches())-1))
+		}
+	case searchResultMsg:
+		if msg.query != s.search {
+			return s, nil
+		}
+		s.searchHits = msg.hits
+		if s.page.more {
+			// Hits may lie past the pages loaded, so load the whole tab.
+			s.seekHit = 1
+			return s, s.loadTodos()
+		}
+		s = s.revealHits()
+		if s.uiState == SearchState {
+			return s.showHits()
+		}
+	case cardMovedMsg:
+		s.followID = msg.id
+		s.status = msg.warning
+		return s, s.loadTodos()
+	case projectChangedMsg:
+		s.uiState = BrowsingState
+		s.editingText = ""
+		s.viewMode = ActiveView
+		s.projectID = msg.projectID
+		s.viewID = 0
+		if msg.projectID != 0 {
+			s.viewMode = ProjectView
+		}
+		s.cursor = 0
 		return s, s.loadTodos()
+	case columnChangedMsg:
+		s.uiState = ColumnsState
+		s.editingText = ""
+		s.columnCursor = msg.cursor
+		return s, s.loadTodos()
+	case viewChangedMsg:
+		if s.uiState == ViewCreateState {
+			s.filter = Filter{}
+		}
+		s.uiState = BrowsingState
+		s.editingText = ""
+		s.viewMode = ActiveView
+		s.projectID = 0
+		s.viewID = msg.viewID
+		if msg.viewID != 0 {
+			s.viewMode = SavedView
+		}
+		s.cursor = 0
+		return s, s.loadTodos()
+	case profileSwitchedMsg:
+		s.database.Close()
+		// Filters, searches, folds, sorts and undo history all refer to the
+		// old database, so start again from a fresh state. Only the window
+		// size carries over, along with the timer's tick loop if one is
+		// running, which stops itself if the new database has no timer.
+		next := InitialState(msg.database, msg.location)
+		next.windowWidth = s.windowWidth
+		next.windowHeight = s.windowHeight
+		next.ticking = s.ticking
+		return next, next.loadTodos()
+	case string:
+		s.message = msg
 	case tea.KeyMsg:
 		return s.handleKeyPress(msg)
 	}
@@ -122,13 +570,71 @@ func (s State) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return s.handleBrowsingKeys(msg)
 	case EditingState, CreatingState:
 		return s.handleEditingKeys(msg)
+	case ProfileState:
+		return s.handleProfileKeys(msg)
+	case DueState:
+		return s.handleDueKeys(msg)
+	case RecurrenceState:
+		return s.handleRecurrenceKeys(msg)
+	case TagState, TagFilterState:
+		return s.handleTagKeys(msg)
+	case ProjectCreateState, ProjectRenameState:
+		return s.handleProjectKeys(msg)
+	case ViewCreateState, ViewRenameState:
+		return s.handleViewKeys(msg)
+	case MoveState:
+		return s.handleMoveKeys(msg)
+	case ColumnsState:
+		return s.handleColumnsKeys(msg)
+	case ColumnCreateState, ColumnRenameState, ColumnLimitState:
+		return s.handleColumnKeys(msg)
+	case ConfirmState:
+		return s.handleConfirmKeys(msg)
+	case NotesState:
+		return s.handleNotesKeys(msg)
+	case HistoryState:
+		return s.handleHistoryKeys(msg)
+	case LinkState:
+		return s.handleLinkKeys(msg)
+	case SearchState:
+		return s.handleSearchKeys(msg)
+	case FilterState:
+		return s.handleFilterKeys(msg)
 	}
 	return s, nil
 }
 
 func (s State) handleBrowsingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s.status = ""
+	pendingG := s.pendingG
+	s.pendingG = false
+	if s.boardActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+		switch msg.String() {
+		case tea.KeyLeft.String(), "h":
+			return s.moveBoardColumn(-1), nil
+		case tea.KeyRight.String(), "l":
+			return s.moveBoardColumn(1), nil
+		case tea.KeyUp.String(), "k":
+			return s.moveBoardRow(-1), nil
+		case tea.KeyDown.String(), "j":
+			return s.moveBoardRow(1), nil
+		case "[":
+			return s, s.shiftCard(-1)
+		case "]":
+			return s, s.shiftCard(1)
+		}
+	}
 	switch msg.String() {
+	case "u":
+		return s.undo()
+	case tea.KeyCtrlR.String():
+		return s.redo()
 	case tea.KeyEsc.String(), "q":
+		if msg.String() == tea.KeyEsc.String() && s.search != "" {
+			s.search = ""
+			s.searchHits = nil
+			return s, nil
+		}
 		return s, tea.Quit
 	case tea.KeyUp.String(), "k":
 		if s.cursor > 0 {
@@ -138,43 +644,434 @@ func (s State) handleBrowsingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if s.cursor < len(s.todos)-1 {
 			s.cursor++
 		}
+	case tea.KeyPgUp.String():
+		s.cursor = max(0, s.cursor-s.listHeight())
+	case tea.KeyPgDown.String():
+		s.cursor = max(0, min(len(s.todos)-1, s.cursor+s.listHeight()))
+	case "g":
+		if pendingG {
+			s.cursor = 0
+		} else {
+			s.pendingG = true
+		}
+	case "G":
+		s.cursor = max(0, len(s.todos)-1)
+		if rest := s.loadRest(); rest != nil {
+			s.fetching = true
+			return s, rest
+		}
+	case "/":
+		if s.viewMode != TrashView {
+			s.uiState = SearchState
+			s.search = ""
+			s.searchHits = nil
+		}
 	case "n":
-		if s.viewMode == ActiveView {
+		if s.searchActive() {
+			return s.nextHit(1)
+		}
+		if s.listingActive() {
+			s.uiState = CreatingState
+			s.editingText = ""
+			s.parentID = 0
+		}
+	case "a":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
 			s.uiState = CreatingState
 			s.editingText = ""
+			s.parentID = s.todos[s.cursor].ID
+		}
+	case tea.KeyRight.String(), "l":
+		if len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.collapsed = withCollapsed(s.collapsed, s.todos[s.cursor].ID, false)
+			s.todos, s.tree = flattenTree(s.loaded, s.collapsed)
+		}
+	case tea.KeyLeft.String(), "h":
+		if len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			if s.tree[todo.ID].hasChildren && !s.collapsed[todo.ID] {
+				s.collapsed = withCollapsed(s.collapsed, todo.ID, true)
+				s.todos, s.tree = flattenTree(s.loaded, s.collapsed)
+			} else if todo.ParentID.Valid {
+				for i, t := range s.todos {
+					if int64(t.ID) == todo.ParentID.Int64 {
+						s.cursor = i
+					}
+				}
+			}
+		}
+	case ">":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			depth := s.tree[todo.ID].depth
+			for i := s.cursor - 1; i >= 0; i-- {
+				d := s.tree[s.todos[i].ID].depth
+				if d < depth {
+					break
+				}
+				if d == depth {
+					s.collapsed = withCollapsed(s.collapsed, s.todos[i].ID, false)
+					return s, s.setParent(todo.ID, s.todos[i].ID)
+				}
+			}
+		}
+	case "<":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			if todo := s.todos[s.cursor]; todo.ParentID.Valid {
+				return s, s.outdentTodo(todo.ID, int(todo.ParentID.Int64))
+			}
 		}
 	case "e":
-		if s.viewMode == ActiveView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
 			s.uiState = EditingState
 			s.editingTodo = &s.todos[s.cursor]
 			s.editingText = s.editingTodo.Content
 		}
-	case " ":
+	case "o":
+		if s.viewMode != TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.uiState = NotesState
+			s.editingTodo = &s.todos[s.cursor]
+			s.notes = newNoteEditor(s.editingTodo.Notes)
+		}
+	case "i":
+		s.showDetail = !s.showDetail
+	case "v":
+		if s.listingActive() {
+			s.showBoard = !s.showBoard
+			if s.showBoard && s.page.more {
+				// The board shows every card at once, so load the rest.
+				return s, s.loadTodos()
+			}
+		}
+	case "C":
+		if s.listingActive() {
+			s.uiState = ColumnsState
+			if s.boardActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+				s.columnCursor, _ = s.boardPosition(boardCards(s.todos, s.columns))
+			}
+		}
+	case "L":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s, s.openLinkPicker(s.todos[s.cursor], false)
+		}
+	case "U":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s, s.openLinkPicker(s.todos[s.cursor], true)
+		}
+	case "B":
+		if s.listingActive() {
+			s.hideBlocked = !s.hideBlocked
+			s.cursor = 0
+			return s, s.loadTodos()
+		}
+	case "S":
+		if s.viewMode != TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			if todo.Completed {
+				s.status = "can't time a completed todo"
+				return s, nil
+			}
+			return s, s.toggleTimer(todo)
+		}
+	case "H":
 		if len(s.todos) > 0 && s.cursor < len(s.todos) {
-			return s, s.toggleTodo(s.todos[s.cursor].ID)
+			return s, s.loadHistory(s.todos[s.cursor])
+		}
+	case " ":
+		if s.viewMode != TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			p := s.progress[todo.ID]
+			if !todo.Completed && p.done < p.total {
+				s.uiState = ConfirmState
+				s.confirmPrompt = fmt.Sprintf("also complete %d open subtask(s) of \"%s\"?", p.total-p.done, todo.Content)
+				s.confirmYes = s.completeWithSubtasks(todo)
+				s.confirmNo = s.toggleTodo(todo)
+				return s, nil
+			}
+			return s, s.toggleTodo(todo)
 		}
 	case "d":
-		if len(s.todos) > 0 && s.cursor < len(s.todos) {
-			return s, s.deleteTodo(s.todos[s.cursor].ID)
+		if s.viewMode == TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			s.uiState = ConfirmState
+			s.confirmPrompt = fmt.Sprintf("permanently delete \"%s\"?", todo.Content)
+			s.confirmYes = s.purgeTodo(todo.ID)
+			s.confirmNo = nil
+		} else if len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s, s.trashTodo(s.todos[s.cursor])
 		}
 	case "p":
-		if s.viewMode == ActiveView && len(s.todos) > 0 && s.cursor < len(s.todos) {
-			return s, s.cyclePriority(s.todos[s.cursor].ID, Priority(s.todos[s.cursor].Priority))
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s, s.cyclePriority(s.todos[s.cursor])
+		}
+	case "w":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			status := StatusDoing
+			if Status(todo.Status) == StatusDoing {
+				status = StatusTodo
+			}
+			return s, s.setStatus(todo, status)
+		}
+	case "b":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			todo := s.todos[s.cursor]
+			status := StatusBlocked
+			if Status(todo.Status) == StatusBlocked {
+				status = StatusTodo
+			}
+			return s, s.setStatus(todo, status)
+		}
+	case "f":
+		if s.listingActive() {
+			s.statusFilter = nextFilter(s.statusFilter)
+			s.cursor = 0
+			return s, s.loadTodos()
+		}
+	case "D":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.uiState = DueState
+			s.editingTodo = &s.todos[s.cursor]
+			s.editingText = ""
+			if s.editingTodo.DueAt.Valid {
+				s.editingText = s.editingTodo.DueAt.Time.Format("2006-01-02")
+			}
+		}
+	case "E":
+		if s.viewMode == TrashView && len(s.todos) > 0 {
+			s.uiState = ConfirmState
+			s.confirmPrompt = fmt.Sprintf("permanently delete all %d todo(s) in the trash?", len(s.loaded))
+			s.confirmYes = s.emptyTrash()
+			s.confirmNo = nil
+		}
+	case "r":
+		if s.viewMode == TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s, s.restoreTodo(s.todos[s.cursor].ID)
+		} else if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.uiState = RecurrenceState
+			s.editingTodo = &s.todos[s.cursor]
+			s.editingText = ""
+			if r, err := ParseRecurrence(s.editingTodo.Recurrence); err == nil {
+				s.editingText = r.Describe()
+			}
+		}
+	case "t":
+		if s.viewMode != TrashView && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.uiState = TagState
+			s.editingTodo = &s.todos[s.cursor]
+			s.editingText = formatTags(s.todoTags[s.editingTodo.ID])
+		}
+	case "T":
+		s.uiState = TagFilterState
+		s.editingText = formatTags(s.tagFilter)
+	case "F":
+		if s.viewMode != TrashView {
+			s.uiState = FilterState
+			s.editingText = s.filter.Source
+		}
+	case "c":
+		if s.filter.Active() {
+			s.filter = Filter{}
+			s.cursor = 0
+			return s, s.loadTodos()
+		}
+	case "s":
+		if s.sortable() {
+			modes := sortModes
+			if s.viewMode == CompletedView {
+				modes = completedSortModes
+			}
+			return s.setSort(s.currentSort().next(modes))
+		}
+	case "O":
+		if s.sortable() {
+			sort := s.currentSort()
+			sort.Desc = !sort.Desc
+			return s.setSort(sort)
+		}
+	case "K":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s.moveAmongSiblings(-1)
+		}
+	case "J":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			return s.moveAmongSiblings(1)
+		}
+	case "N":
+		if s.searchActive() {
+			return s.nextHit(-1)
+		}
+		s.uiState = ProjectCreateState
+		s.editingText = ""
+	case "R":
+		if s.viewMode == ProjectView {
+			s.uiState = ProjectRenameState
+			s.editingText = projectName(s.projects, nullID(s.projectID))
+		} else if s.viewMode == SavedView {
+			s.uiState = ViewRenameState
+			s.editingText = viewName(s.views, s.viewID)
+		}
+	case "X":
+		if s.viewMode == ProjectView {
+			return s, s.deleteProject(s.projectID)
+		} else if s.viewMode == SavedView {
+			return s, s.deleteView(s.viewID)
 		}
+	case "V":
+		if !s.filter.Active() {
+			s.message = "apply a filter with F first, then V saves it as a view"
+			return s, nil
+		}
+		s.uiState = ViewCreateState
+		s.editingText = ""
+	case "m":
+		if s.listingActive() && len(s.todos) > 0 && s.cursor < len(s.todos) {
+			s.uiState = MoveState
+			s.editingTodo = &s.todos[s.cursor]
+			s.moveCursor = 0
+			for i, p := range s.projects {
+				if s.editingTodo.ProjectID.Valid && int64(p.ID) == s.editingTodo.ProjectID.Int64 {
+					s.moveCursor = i + 1
+				}
+			}
+		}
+	case "P":
+		profiles, err := ListProfiles()
+		if err != nil {
+			s.message = fmt.Sprintf("Error listing profiles: %v", err)
+			return s, nil
+		}
+		s.uiState = ProfileState
+		s.profiles = profiles
+		s.profileCursor = 0
+		for i, name := range profiles {
+			if name == s.location.Profile {
+				s.profileCursor = i
+			}
+		}
+		s.editingText = ""
 	case "?":
 		s.showHelp = !s.showHelp
 	case tea.KeyTab.String():
-		if s.viewMode == ActiveView {
-			s.viewMode = CompletedView
-		} else {
-			s.viewMode = ActiveView
-		}
+		s = s.nextTab()
 		s.cursor = 0
 		return s, s.loadTodos()
 	}
 	return s, nil
 }
 
+// sortable reports whether the current tab's order can be changed. The
+// trash is always listed most recently deleted first.
+func (s State) sortable() bool {
+	return s.listingActive() || s.viewMode == CompletedView
+}
+
+// tabKey names the current tab for remembering its sort.
+func (s State) tabKey() string {
+	switch s.viewMode {
+	case CompletedView:
+		return "complete"
+	case TrashView:
+		return "trash"
+	case ProjectView:
+		return projectTab(s.projectID)
+	case SavedView:
+		return viewTab(s.viewID)
+	}
+	return "active"
+}
+
+// currentSort is the order of the current tab, its default until one is
+// chosen.
+func (s State) currentSort() Sort {
+	if sort, ok := s.sorts[s.tabKey()]; ok {
+		return sort
+	}
+	return defaultSort(s.viewMode == CompletedView)
+}
+
+// setSort changes the current tab's order and remembers it for next time.
+func (s State) setSort(sort Sort) (State, tea.Cmd) {
+	tab := s.tabKey()
+	s.sorts = withSort(s.sorts, tab, sort)
+	return s, tea.Cmd(func() tea.Msg {
+		if err := s.database.SetTabSort(context.Background(), tab, sort); err != nil {
+			return tea.Msg(fmt.Sprintf("Error saving sort order: %v", err))
+		}
+		return sortChangedMsg{}
+	})
+}
+
+func withSort(sorts map[string]Sort, tab string, sort Sort) map[string]Sort {
+	next := maps.Clone(sorts)
+	if next == nil {
+		next = map[string]Sort{}
+	}
+	next[tab] = sort
+	return next
+}
+
+// listingActive reports whether the current tab shows incomplete todos,
+// either all of them or those of a single project or saved view.
+func (s State) listingActive() bool {
+	return s.viewMode == ActiveView || s.viewMode == ProjectView || s.viewMode == SavedView
+}
+
+// nextTab cycles active, then each project in creation order, then each saved
+// view in creation order, then complete, then trash.
+func (s State) nextTab() State {
+	switch s.viewMode {
+	case ActiveView:
+		if len(s.projects) > 0 {
+			s.viewMode = ProjectView
+			s.projectID = s.projects[0].ID
+		} else {
+			s = s.firstView()
+		}
+	case ProjectView:
+		s = s.firstView()
+		for i, p := range s.projects {
+			if p.ID == s.projectID && i+1 < len(s.projects) {
+				s.viewMode = ProjectView
+				s.projectID = s.projects[i+1].ID
+				break
+			}
+		}
+	case SavedView:
+		s.viewMode = CompletedView
+		for i, v := range s.views {
+			if v.ID == s.viewID && i+1 < len(s.views) {
+				s.viewMode = SavedView
+				s.viewID = s.views[i+1].ID
+				break
+			}
+		}
+	case CompletedView:
+		s.viewMode = TrashView
+	default:
+		s.viewMode = ActiveView
+	}
+	if s.viewMode != ProjectView {
+		s.projectID = 0
+	}
+	if s.viewMode != SavedView {
+		s.viewID = 0
+	}
+	s.page = todoPage{}
+	return s
+}
+
+// firstView moves to the first saved view's tab, or to complete if there are
+// none.
+func (s State) firstView() State {
+	s.viewMode = CompletedView
+	if len(s.views) > 0 {
+		s.viewMode = SavedView
+		s.viewID = s.views[0].ID
+	}
+	return s
+}
+
 func (s State) handleEditingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case tea.KeyEsc.String():
@@ -185,10 +1082,18 @@ func (s State) handleEditingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if strings.TrimSpace(s.editingText) == "" {
 			return s, nil
 		}
-		if s.uiState == CreatingState {
-			return s, s.createTodo(s.editingText)
+		if s.uiState == CreatingState && s.parentID != 0 {
+			project := s.projectID
+			for _, t := range s.loaded {
+				if t.ID == s.parentID && t.ProjectID.Valid {
+					project = int(t.ProjectID.Int64)
+				}
+			}
+			return s, s.createTodo(s.editingText, project, s.parentID)
+		} else if s.uiState == CreatingState {
+			return s, s.createTodo(s.editingText, s.projectID, 0)
 		} else if s.uiState == EditingState && s.editingTodo != nil {
-			return s, s.updateTodo(s.editingTodo.ID, s.editingText)
+			return s, s.updateTodo(*s.editingTodo, s.editingText)
 		}
 	case tea.KeyBackspace.String():
 		if len(s.editingText) > 0 {
@@ -202,67 +1107,728 @@ func (s State) handleEditingKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return s, nil
 }
 
-func (s State) createTodo(content string) tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		ctx := context.Background()
-		now := time.Now()
-		todo, err := s.database.Queries.CreateTodo(ctx, orm.CreateTodoParams{
-			Content:   content,
-			Priority:  string(P2),
-			CreatedAt: now,
-			UpdatedAt: now,
-		})
-		if err != nil {
-			return tea.Msg(fmt.Sprintf("Error creating todo: %v", err))
+// handleProfileKeys drives the profile picker. Typing a name switches to (and
+// creates, if needed) that profile; otherwise the highlighted one is used.
+func (s State) handleProfileKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingText = ""
+	case tea.KeyUp.String():
+		if s.profileCursor > 0 {
+			s.profileCursor--
 		}
-		return todoCreatedMsg{todo: &todo}
-	})
+	case tea.KeyDown.String():
+		if s.profileCursor < len(s.profiles)-1 {
+			s.profileCursor++
+		}
+	case tea.KeyEnter.String():
+		name := strings.TrimSpace(s.editingText)
+		if name == "" && s.profileCursor < len(s.profiles) {
+			name = s.profiles[s.profileCursor]
+		}
+		if err := ValidateProfileName(name); err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		if name == s.location.Profile {
+			s.uiState = BrowsingState
+			s.editingText = ""
+			return s, nil
+		}
+		return s, s.switchProfile(name)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
 }
 
-func (s State) updateTodo(id int, content string) tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		ctx := context.Background()
-		now := time.Now()
-		err := s.database.Queries.UpdateTodoContent(ctx, orm.UpdateTodoContentParams{
-			ID:        id,
-			Content:   content,
-			UpdatedAt: now,
-		})
+// handleDueKeys drives the due date form. Submitting an empty date clears it.
+func (s State) handleDueKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		if s.editingTodo == nil {
+			return s, nil
+		}
+		if strings.TrimSpace(s.editingText) == "" {
+			return s, s.clearDue(s.editingTodo.ID)
+		}
+		due, err := ParseDue(s.editingText, time.Now())
 		if err != nil {
-			return tea.Msg(fmt.Sprintf("Error updating todo: %v", err))
+			s.message = err.Error()
+			return s, nil
+		}
+		return s, s.setDue(s.editingTodo.ID, due)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleRecurrenceKeys drives the recurrence form. Submitting an empty rule
+// stops the todo repeating.
+func (s State) handleRecurrenceKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		if s.editingTodo == nil {
+			return s, nil
+		}
+		if strings.TrimSpace(s.editingText) == "" {
+			return s, s.setRecurrence(s.editingTodo.ID, "")
+		}
+		r, err := ParseRecurrence(s.editingText)
+		if err == nil {
+			_, err = nextDue(r, s.editingTodo.DueAt.Time, s.editingTodo.DueAt.Valid, time.Now())
+		}
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		return s, s.setRecurrence(s.editingTodo.ID, r.String())
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleTagKeys drives both the tag editor for the selected todo and the tag
+// filter form. Tags are separated by spaces or commas; '#' is optional.
+func (s State) handleTagKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		tags, err := ParseTags(s.editingText)
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		if s.uiState == TagFilterState {
+			s.uiState = BrowsingState
+			s.editingText = ""
+			s.tagFilter = tags
+			s.cursor = 0
+			return s, s.loadTodos()
+		}
+		if s.editingTodo != nil {
+			return s, s.setTags(s.editingTodo.ID, tags)
+		}
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleFilterKeys drives the filter query form. An empty query clears the
+// filter.
+func (s State) handleFilterKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		filter, err := ParseFilter(s.editingText, time.Now())
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		s.uiState = BrowsingState
+		s.editingText = ""
+		s.filter = filter
+		s.cursor = 0
+		return s, s.loadTodos()
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleProjectKeys drives the create and rename project forms.
+func (s State) handleProjectKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		name, err := NormalizeProjectName(s.editingText)
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		if s.uiState == ProjectCreateState {
+			return s, s.createProject(name)
+		}
+		return s, s.renameProject(s.projectID, name)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleViewKeys drives the forms that save the current filter as a view and
+// rename a view.
+func (s State) handleViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		name, err := NormalizeViewName(s.editingText)
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		if s.uiState == ViewCreateState {
+			return s, s.createView(name, s.filter.Source)
+		}
+		return s, s.renameView(s.viewID, name)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleMoveKeys drives the project picker used to move the selected todo.
+// The first entry takes the todo out of any project.
+func (s State) handleMoveKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String(), "q":
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+	case tea.KeyUp.String(), "k":
+		if s.moveCursor > 0 {
+			s.moveCursor--
+		}
+	case tea.KeyDown.String(), "j":
+		if s.moveCursor < len(s.projects) {
+			s.moveCursor++
+		}
+	case tea.KeyEnter.String():
+		if s.editingTodo == nil {
+			return s, nil
+		}
+		target := 0
+		if s.moveCursor > 0 && s.moveCursor <= len(s.projects) {
+			target = s.projects[s.moveCursor-1].ID
+		}
+		return s, s.moveTodo(s.editingTodo.ID, target)
+	}
+	return s, nil
+}
+
+// handleColumnsKeys drives the board column manager, where columns are
+// added, renamed, limited, and deleted.
+func (s State) handleColumnsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String(), "q":
+		s.uiState = BrowsingState
+	case tea.KeyUp.String(), "k":
+		if s.columnCursor > 0 {
+			s.columnCursor--
+		}
+	case tea.KeyDown.String(), "j":
+		if s.columnCursor < len(s.columns)-1 {
+			s.columnCursor++
+		}
+	case "n":
+		s.uiState = ColumnCreateState
+		s.editingText = ""
+	case "r":
+		if s.columnCursor < len(s.columns) {
+			s.uiState = ColumnRenameState
+			s.editingText = s.columns[s.columnCursor].Name
+		}
+	case "w":
+		if s.columnCursor < len(s.columns) {
+			s.uiState = ColumnLimitState
+			s.editingText = ""
+			if limit := s.columns[s.columnCursor].WipLimit; limit > 0 {
+				s.editingText = strconv.Itoa(limit)
+			}
+		}
+	case "d":
+		if s.columnCursor < len(s.columns) {
+			column := s.columns[s.columnCursor]
+			s.uiState = ConfirmState
+			s.confirmPrompt = fmt.Sprintf("delete column \"%s\"? its cards move to the first column", column.Name)
+			s.confirmYes = s.deleteColumn(column.ID, max(0, s.columnCursor-1))
+			s.confirmNo = nil
+		}
+	}
+	return s, nil
+}
+
+// handleColumnKeys drives the forms that add a board column, rename one, and
+// set its WIP limit. Esc goes back to the column manager.
+func (s State) handleColumnKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = ColumnsState
+		s.editingText = ""
+	case tea.KeyEnter.String():
+		if s.uiState == ColumnLimitState {
+			limit, err := ParseWipLimit(s.editingText)
+			if err != nil {
+				s.message = err.Error()
+				return s, nil
+			}
+			if s.columnCursor >= len(s.columns) {
+				return s, nil
+			}
+			return s, s.setWipLimit(s.columns[s.columnCursor].ID, limit, s.columnCursor)
+		}
+		name, err := NormalizeColumnName(s.editingText)
+		if err != nil {
+			s.message = err.Error()
+			return s, nil
+		}
+		if s.uiState == ColumnCreateState {
+			return s, s.createColumn(name)
+		}
+		if s.columnCursor >= len(s.columns) {
+			return s, nil
+		}
+		return s, s.renameColumn(s.columns[s.columnCursor].ID, name, s.columnCursor)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+		}
+	}
+	return s, nil
+}
+
+// handleHistoryKeys browses the selected todo's history. Enter on a create
+// or edit event puts that revision's content back; the restore is itself an
+// edit, so it can be undone.
+func (s State) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String(), "q":
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.history = nil
+	case tea.KeyUp.String(), "k":
+		if s.historyCursor > 0 {
+			s.historyCursor--
+		}
+	case tea.KeyDown.String(), "j":
+		if s.historyCursor < len(s.history)-1 {
+			s.historyCursor++
+		}
+	case tea.KeyEnter.String():
+		if s.editingTodo == nil || s.historyCursor >= len(s.history) {
+			return s, nil
+		}
+		content, ok := eventContent(s.history[s.historyCursor])
+		if !ok || content == s.editingTodo.Content {
+			return s, nil
+		}
+		s.history = nil
+		return s, s.updateTodo(*s.editingTodo, content)
+	}
+	return s, nil
+}
+
+// handleLinkKeys drives the picker for linking the selected todo to a blocker,
+// or unlinking one. Typing narrows the list by content or #id, so only the
+// arrow keys move the cursor.
+func (s State) handleLinkKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	matches := s.linkMatches()
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.editingText = ""
+		s.picks = nil
+		s.message = ""
+	case tea.KeyUp.String():
+		if s.pickCursor > 0 {
+			s.pickCursor--
+		}
+	case tea.KeyDown.String():
+		if s.pickCursor < len(matches)-1 {
+			s.pickCursor++
+		}
+	case tea.KeyEnter.String():
+		if s.editingTodo == nil || s.pickCursor >= len(matches) {
+			return s, nil
+		}
+		return s, s.setLink(s.editingTodo.ID, matches[s.pickCursor].ID, s.unlinking)
+	case tea.KeyBackspace.String():
+		if len(s.editingText) > 0 {
+			s.editingText = s.editingText[:len(s.editingText)-1]
+			s.pickCursor = 0
+			return s, s.refreshLinkPicks()
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.editingText += msg.String()
+			s.pickCursor = 0
+			return s, s.refreshLinkPicks()
+		}
+	}
+	return s, nil
+}
+
+// refreshLinkPicks looks up the todos matching the typed text when linking.
+// The blockers offered for unlinking are all at hand already.
+func (s State) refreshLinkPicks() tea.Cmd {
+	if s.unlinking || s.editingTodo == nil {
+		return nil
+	}
+	return s.findLinkPicks(s.editingTodo.ID, s.editingText)
+}
+
+func (s State) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEsc.String():
+		s.uiState = BrowsingState
+		s.search = ""
+		s.searchHits = nil
+	case tea.KeyEnter.String():
+		s.uiState = BrowsingState
+		if len(s.searchHits) == 0 {
+			if s.search != "" {
+				s.status = fmt.Sprintf("no todos match %q", s.search)
+			}
+			s.search = ""
+			s.searchHits = nil
+		}
+	case tea.KeyBackspace.String():
+		if len(s.search) > 0 {
+			s.search = s.search[:len(s.search)-1]
+			return s, s.runSearch()
+		}
+	default:
+		if len(msg.String()) == 1 {
+			s.search += msg.String()
+			return s, s.runSearch()
+		}
+	}
+	return s, nil
+}
+
+// linkMatches filters the picker's todos by the typed text, so the list
+// narrows at once while the lookup for the new text runs.
+func (s State) linkMatches() []orm.Todo {
+	query := strings.ToLower(strings.TrimSpace(s.editingText))
+	if query == "" {
+		return s.picks
+	}
+	var matches []orm.Todo
+	for _, todo := range s.picks {
+		if strings.Contains(strings.ToLower(todo.Content), query) || fmt.Sprintf("#%d", todo.ID) == query {
+			matches = append(matches, todo)
+		}
+	}
+	return matches
+}
+
+// moveAmongSiblings swaps the selected todo with its previous (dir -1) or
+// next (dir 1) sibling, carrying subtasks along. The list switches to manual
+// order so that the current arrangement, plus the swap, is what gets saved.
+func (s State) moveAmongSiblings(dir int) (tea.Model, tea.Cmd) {
+	todo := s.todos[s.cursor]
+	depth := s.tree[todo.ID].depth
+	sibling := -1
+	for i := s.cursor + dir; i >= 0 && i < len(s.todos); i += dir {
+		d := s.tree[s.todos[i].ID].depth
+		if d < depth {
+			break
+		}
+		if d == depth {
+			sibling = i
+			break
+		}
+	}
+	if sibling < 0 {
+		return s, nil
+	}
+	order := make([]int, len(s.loaded))
+	for i, t := range s.loaded {
+		switch t.ID {
+		case todo.ID:
+			order[i] = s.todos[sibling].ID
+		case s.todos[sibling].ID:
+			order[i] = todo.ID
+		default:
+			order[i] = t.ID
+		}
+	}
+	s.sorts = withSort(s.sorts, s.tabKey(), Sort{Mode: SortManual})
+	return s, s.reorder(order, todo.ID)
+}
+
+// handleNotesKeys drives the multi-line notes editor. Enter starts a new
+// line, so saving is bound to ctrl+s.
+func (s State) handleNotesKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		s.uiState = BrowsingState
+		s.editingTodo = nil
+		s.notes = noteEditor{}
+	case tea.KeyCtrlS:
+		if s.editingTodo != nil {
+			return s, s.updateNotes(s.editingTodo.ID, s.notes.Text())
+		}
+	case tea.KeyEnter:
+		s.notes = s.notes.newline()
+	case tea.KeyBackspace:
+		s.notes = s.notes.backspace()
+	case tea.KeyUp:
+		s.notes = s.notes.moveRow(-1)
+	case tea.KeyDown:
+		s.notes = s.notes.moveRow(1)
+	case tea.KeyPgUp:
+		s.notes = s.notes.moveRow(-noteEditorHeight)
+	case tea.KeyPgDown:
+		s.notes = s.notes.moveRow(noteEditorHeight)
+	case tea.KeyLeft:
+		s.notes = s.notes.moveCol(-1)
+	case tea.KeyRight:
+		s.notes = s.notes.moveCol(1)
+	case tea.KeyHome, tea.KeyCtrlA:
+		s.notes = s.notes.home()
+	case tea.KeyEnd, tea.KeyCtrlE:
+		s.notes = s.notes.end()
+	case tea.KeyTab:
+		s.notes = s.notes.insert([]rune("    "))
+	case tea.KeySpace:
+		s.notes = s.notes.insert([]rune{' '})
+	case tea.KeyRunes:
+		s.notes = s.notes.insert(msg.Runes)
+	}
+	return s, nil
+}
+
+// handleConfirmKeys answers a yes/no prompt. Escape cancels without running
+// either command.
+func (s State) handleConfirmKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "y", "Y", tea.KeyEnter.String():
+		cmd = s.confirmYes
+	case "n", "N":
+		cmd = s.confirmNo
+	case tea.KeyEsc.String(), "q":
+	default:
+		return s, nil
+	}
+	s.uiState = BrowsingState
+	s.confirmPrompt = ""
+	s.confirmYes = nil
+	s.confirmNo = nil
+	return s, cmd
+}
+
+// withCollapsed returns a copy of collapsed with id folded or unfolded, so
+// earlier States never see the change.
+func withCollapsed(collapsed map[int]bool, id int, fold bool) map[int]bool {
+	next := maps.Clone(collapsed)
+	if next == nil {
+		next = map[int]bool{}
+	}
+	if fold {
+		next[id] = true
+	} else {
+		delete(next, id)
+	}
+	return next
+}
+
+func formatTags(tags []string) string {
+	var out []string
+	for _, tag := range tags {
+		out = append(out, "#"+tag)
+	}
+	return strings.Join(out, " ")
+}
+
+func (s State) switchProfile(name string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		path, err := ProfilePath(name)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error switching profile: %v", err))
+		}
+		database, err := NewDatabase(path, s.database.TrashDays)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error switching profile: %v", err))
+		}
+		return profileSwitchedMsg{
+			database: database,
+			location: DBLocation{Path: path, Profile: name},
 		}
-		return todoUpdatedMsg{success: true}
 	})
 }
 
-func (s State) toggleTodo(id int) tea.Cmd {
+func (s State) createTodo(content string, project int, parent int) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		ctx := context.Background()
 		now := time.Now()
-		err := s.database.Queries.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{
-			ID:        id,
+		todo, err := s.database.CreateTodo(ctx, orm.CreateTodoParams{
+			Content:   content,
+			Priority:  string(P2),
+			CreatedAt: now,
 			UpdatedAt: now,
+			ProjectID: nullID(project),
+			ParentID:  nullID(parent),
 		})
 		if err != nil {
-			return tea.Msg(fmt.Sprintf("Error toggling todo: %v", err))
+			return tea.Msg(fmt.Sprintf("Error creating todo: %v", err))
+		}
+		return todoCreatedMsg{todo: &todo}
+	})
+}
+
+func (s State) updateTodo(todo orm.Todo, content string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.UpdateContent(ctx, todo.ID, content); err != nil {
+			return tea.Msg(fmt.Sprintf("Error updating todo: %v", err))
+		}
+		return todoUpdatedMsg{success: true, action: setContentAction(todo.ID, todo.Content, content)}
+	})
+}
+
+func (s State) loadHistory(todo orm.Todo) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		events, err := s.database.Queries.GetTodoEvents(context.Background(), todo.ID)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading history: %v", err))
+		}
+		return historyLoadedMsg{todo: todo, events: events}
+	})
+}
+
+func (s State) updateNotes(id int, notes string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		err := s.database.Queries.UpdateTodoNotes(ctx, orm.UpdateTodoNotesParams{
+			ID:        id,
+			Notes:     notes,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error saving notes: %v", err))
 		}
 		return todoUpdatedMsg{success: true}
 	})
 }
 
-func (s State) deleteTodo(id int) tea.Cmd {
+func (s State) toggleTodo(todo orm.Todo) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
 		ctx := context.Background()
-		err := s.database.Queries.DeleteTodo(ctx, id)
+		running, err := s.database.RunningTimer(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading timer: %v", err))
+		}
+		next, err := s.database.ToggleTodo(ctx, todo.ID)
 		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error toggling todo: %v", err))
+		}
+		return todoUpdatedMsg{success: true, action: toggleAction(todo, next, stoppedTimer(running, todo.ID))}
+	})
+}
+
+func (s State) trashTodo(todo orm.Todo) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.Trash(ctx, todo.ID); err != nil {
 			return tea.Msg(fmt.Sprintf("Error deleting todo: %v", err))
 		}
+		return todoDeletedMsg{success: true, action: trashAction(fmt.Sprintf("delete %q", todo.Content), todo.ID, true)}
+	})
+}
+
+func (s State) restoreTodo(id int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.Restore(ctx, id); err != nil {
+			return tea.Msg(fmt.Sprintf("Error restoring todo: %v", err))
+		}
+		return todoDeletedMsg{success: true}
+	})
+}
+
+// purgeTodo deletes a trashed todo, and its subtasks, for good.
+func (s State) purgeTodo(id int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.Purge(ctx, id); err != nil {
+			return tea.Msg(fmt.Sprintf("Error purging todo: %v", err))
+		}
+		return todoDeletedMsg{success: true}
+	})
+}
+
+func (s State) emptyTrash() tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if _, err := s.database.EmptyTrash(ctx); err != nil {
+			return tea.Msg(fmt.Sprintf("Error emptying trash: %v", err))
+		}
 		return todoDeletedMsg{success: true}
 	})
 }
 
-func (s State) cyclePriority(id int, prev Priority) tea.Cmd {
+func (s State) cyclePriority(todo orm.Todo) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
+		prev := Priority(todo.Priority)
 		var next Priority
 		switch prev {
 		case P2:
@@ -274,16 +1840,350 @@ func (s State) cyclePriority(id int, prev Priority) tea.Cmd {
 		default:
 			next = P2
 		}
+		ctx := context.Background()
+		if err := s.database.UpdatePriority(ctx, todo.ID, next); err != nil {
+			return tea.Msg(fmt.Sprintf("Error updating priority: %v", err))
+		}
+		return todoUpdatedMsg{success: true, action: setPriorityAction(todo.ID, todo.Content, prev, next)}
+	})
+}
+
+func (s State) setStatus(todo orm.Todo, status Status) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		running, err := s.database.RunningTimer(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading timer: %v", err))
+		}
+		next, err := s.database.SetStatus(ctx, todo.ID, status)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error setting status: %v", err))
+		}
+		var stopped *orm.TimeEntry
+		if status == StatusDone {
+			stopped = stoppedTimer(running, todo.ID)
+		}
+		return todoUpdatedMsg{success: true, action: statusAction(todo, status, next, stopped)}
+	})
+}
+
+func (s State) setDue(id int, due time.Time) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
 		ctx := context.Background()
 		now := time.Now()
-		err := s.database.Queries.UpdateTodoPriority(ctx, orm.UpdateTodoPriorityParams{
+		err := s.database.Queries.SetTodoDueAt(ctx, orm.SetTodoDueAtParams{
 			ID:        id,
-			Priority:  string(next),
+			DueAt:     sql.NullTime{Time: due, Valid: true},
 			UpdatedAt: now,
 		})
 		if err != nil {
-			return tea.Msg(fmt.Sprintf("Error updating priority: %v", err))
+			return tea.Msg(fmt.Sprintf("Error setting due date: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+func (s State) clearDue(id int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		now := time.Now()
+		err := s.database.Queries.ClearTodoDueAt(ctx, orm.ClearTodoDueAtParams{
+			ID:        id,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error clearing due date: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+func (s State) setRecurrence(id int, rule string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		err := s.database.Queries.SetTodoRecurrence(ctx, orm.SetTodoRecurrenceParams{
+			ID:         id,
+			Recurrence: rule,
+			UpdatedAt:  time.Now(),
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error setting recurrence: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+// reorder saves a hand-made order, switching the tab to manual sorting so it
+// shows, and keeps the cursor on the moved todo.
+func (s State) reorder(order []int, moved int) tea.Cmd {
+	tab := s.tabKey()
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.SetTabSort(ctx, tab, Sort{Mode: SortManual}); err != nil {
+			return tea.Msg(fmt.Sprintf("Error saving sort order: %v", err))
+		}
+		if err := s.database.Reorder(ctx, order); err != nil {
+			return tea.Msg(fmt.Sprintf("Error reordering todos: %v", err))
+		}
+		return todoReorderedMsg{id: moved}
+	})
+}
+
+func (s State) setTags(id int, tags []string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.SetTags(ctx, id, tags); err != nil {
+			return tea.Msg(fmt.Sprintf("Error updating tags: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+// openLinkPicker loads the todos the picker offers: the first open todos not
+// already blocking this one when linking, or its current blockers when
+// unlinking.
+func (s State) openLinkPicker(todo orm.Todo, unlinking bool) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		ids, err := s.database.Queries.GetBlockers(ctx, todo.ID)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading dependencies: %v", err))
+		}
+		var picks []orm.Todo
+		if unlinking {
+			for _, id := range ids {
+				blocker, err := s.database.Queries.GetTodo(ctx, id)
+				if err != nil {
+					return tea.Msg(fmt.Sprintf("Error loading dependencies: %v", err))
+				}
+				picks = append(picks, blocker)
+			}
+		} else if picks, err = s.database.LinkCandidates(ctx, todo.ID, ""); err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading todos: %v", err))
+		}
+		return linkPickerMsg{todo: todo, picks: picks, unlinking: unlinking}
+	})
+}
+
+// findLinkPicks reads the todos matching what has been typed into the link
+// picker, since it only holds the first few open todos.
+func (s State) findLinkPicks(id int, query string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		picks, err := s.database.LinkCandidates(context.Background(), id, query)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading todos: %v", err))
+		}
+		return linkPicksMsg{query: query, picks: picks}
+	})
+}
+
+func (s State) setLink(id, blocker int, unlinking bool) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if unlinking {
+			if err := s.database.Unlink(ctx, id, blocker); err != nil {
+				return tea.Msg(fmt.Sprintf("Error unlinking todo: %v", err))
+			}
+		} else if err := s.database.Link(ctx, id, blocker); err != nil {
+			return tea.Msg(fmt.Sprintf("Error linking todo: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+func (s State) moveTodo(id int, project int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		now := time.Now()
+		err := s.database.Queries.MoveTodoToProject(ctx, orm.MoveTodoToProjectParams{
+			ID:        id,
+			ProjectID: nullID(project),
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error moving todo: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+func (s State) createProject(name string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		project, err := s.database.CreateProject(ctx, name)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error creating project: %v", err))
+		}
+		return projectChangedMsg{projectID: project.ID}
+	})
+}
+
+func (s State) renameProject(id int, name string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		err := s.database.Queries.RenameProject(ctx, orm.RenameProjectParams{
+			ID:   id,
+			Name: name,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error renaming project: %v", err))
+		}
+		return projectChangedMsg{projectID: id}
+	})
+}
+
+// deleteProject removes a project. Its todos stay behind, unassigned.
+func (s State) deleteProject(id int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.Queries.DeleteProject(ctx, id); err != nil {
+			return tea.Msg(fmt.Sprintf("Error deleting project: %v", err))
+		}
+		if err := s.database.Queries.DeleteTabSort(ctx, projectTab(id)); err != nil {
+			return tea.Msg(fmt.Sprintf("Error deleting project: %v", err))
+		}
+		return projectChangedMsg{projectID: 0}
+	})
+}
+
+// createColumn adds a column at the right end of the board and selects it in
+// the column manager.
+func (s State) createColumn(name string) tea.Cmd {
+	cursor := len(s.columns)
+	return tea.Cmd(func() tea.Msg {
+		if _, err := s.database.CreateColumn(context.Background(), name); err != nil {
+			return tea.Msg(fmt.Sprintf("Error creating column: %v", err))
+		}
+		return columnChangedMsg{cursor: cursor}
+	})
+}
+
+func (s State) renameColumn(id int, name string, cursor int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := s.database.RenameColumn(context.Background(), id, name); err != nil {
+			return tea.Msg(fmt.Sprintf("Error renaming column: %v", err))
+		}
+		return columnChangedMsg{cursor: cursor}
+	})
+}
+
+// setWipLimit caps a column's cards; a limit of 0 lifts the cap.
+func (s State) setWipLimit(id int, limit int, cursor int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		err := s.database.Queries.SetColumnWipLimit(context.Background(), orm.SetColumnWipLimitParams{
+			ID:       id,
+			WipLimit: limit,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error setting WIP limit: %v", err))
+		}
+		return columnChangedMsg{cursor: cursor}
+	})
+}
+
+// deleteColumn removes a board column. Its cards fall back to the first
+// column.
+func (s State) deleteColumn(id int, cursor int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		if err := s.database.Queries.DeleteColumn(context.Background(), id); err != nil {
+			return tea.Msg(fmt.Sprintf("Error deleting column: %v", err))
+		}
+		return columnChangedMsg{cursor: cursor}
+	})
+}
+
+// createView saves query as a view. The filter it came from is cleared so
+// the new tab isn't narrowed twice.
+func (s State) createView(name, query string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		view, err := s.database.CreateView(ctx, name, query)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error saving view: %v", err))
+		}
+		return viewChangedMsg{viewID: view.ID}
+	})
+}
+
+func (s State) renameView(id int, name string) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		err := s.database.Queries.RenameView(ctx, orm.RenameViewParams{
+			ID:   id,
+			Name: name,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error renaming view: %v", err))
+		}
+		return viewChangedMsg{viewID: id}
+	})
+}
+
+func (s State) deleteView(id int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		if err := s.database.Queries.DeleteView(ctx, id); err != nil {
+			return tea.Msg(fmt.Sprintf("Error deleting view: %v", err))
+		}
+		if err := s.database.Queries.DeleteTabSort(ctx, viewTab(id)); err != nil {
+			return tea.Msg(fmt.Sprintf("Error deleting view: %v", err))
+		}
+		return viewChangedMsg{viewID: 0}
+	})
+}
+
+func (s State) setParent(id int, parent int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		now := time.Now()
+		err := s.database.Queries.SetTodoParent(ctx, orm.SetTodoParentParams{
+			ID:        id,
+			ParentID:  nullID(parent),
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error moving subtask: %v", err))
+		}
+		return todoUpdatedMsg{success: true}
+	})
+}
+
+// outdentTodo moves a subtask up one level, making it a sibling of its
+// current parent.
+func (s State) outdentTodo(id int, parent int) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		p, err := s.database.Queries.GetTodo(ctx, parent)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error moving subtask: %v", err))
+		}
+		err = s.database.Queries.SetTodoParent(ctx, orm.SetTodoParentParams{
+			ID:        id,
+			ParentID:  p.ParentID,
+			UpdatedAt: time.Now(),
+		})
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error moving subtask: %v", err))
 		}
 		return todoUpdatedMsg{success: true}
 	})
 }
+
+func (s State) completeWithSubtasks(todo orm.Todo) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		running, err := s.database.RunningTimer(ctx)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading timer: %v", err))
+		}
+		subtasks, next, err := s.database.CompleteWithSubtasks(ctx, todo.ID)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error completing subtasks: %v", err))
+		}
+		ids := []int{todo.ID}
+		for _, t := range subtasks {
+			ids = append(ids, t.ID)
+		}
+		return todoUpdatedMsg{success: true, action: completeSubtreeAction(todo, subtasks, next, stoppedTimer(running, ids...))}
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// TestProfileSwitchResetsState checks that nothing tied to the old
+// database's todos survives a switch to another profile.
+func TestProfileSwitchResetsState(t *testing.T) {
+	old := newTestDatabase(t)
+	todos := createTestTodos(t, old, "deploy")
+	filter, err := ParseFilter("priority:P0", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := InitialState(old, DBLocation{Profile: "home"})
+	s.windowWidth, s.windowHeight = 120, 40
+	s.search = "deploy"
+	s.searchHits = map[int]bool{todos[0].ID: false}
+	s.filter = filter
+	s.statusFilter = StatusDoing
+	s.hideBlocked = true
+	s.tagFilter = []string{"work"}
+	s.collapsed = map[int]bool{todos[0].ID: true}
+	s.sorts = map[string]Sort{"active": {Mode: SortCreated}}
+	s.viewMode = CompletedView
+	s.cursor = 3
+	s.undoStack = []action{{label: "edit"}}
+
+	next := newTestDatabase(t)
+	m, cmd := s.Update(profileSwitchedMsg{database: next, location: DBLocation{Profile: "work"}})
+	got := m.(State)
+	if cmd == nil {
+		t.Error("switching profiles should load the new database's todos")
+	}
+	if got.database != next || got.location.Profile != "work" {
+		t.Errorf("switched to %q, want work", got.location.Profile)
+	}
+	if got.windowWidth != 120 || got.windowHeight != 40 {
+		t.Errorf("window = %dx%d, want it kept at 120x40", got.windowWidth, got.windowHeight)
+	}
+	if got.search != "" || got.searchHits != nil || got.filter.Active() || got.statusFilter != "" ||
+		got.hideBlocked || got.tagFilter != nil || len(got.collapsed) != 0 || got.sorts != nil ||
+		got.viewMode != ActiveView || got.cursor != 0 || got.undoStack != nil {
+		t.Errorf("state from the old profile survived the switch: %+v", got)
+	}
+}
+
+// TestTabCounts checks the counts read for the tabs, and that the tabs are
+// drawn from them without a database to ask.
+func TestTabCounts(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todos := createTestTodos(t, d, "deploy", "write notes", "buy milk", "old")
+	project, err := d.CreateProject(ctx, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Queries.MoveTodoToProject(ctx, orm.MoveTodoToProjectParams{ProjectID: nullID(project.ID), UpdatedAt: time.Now(), ID: todos[2].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := d.CreateView(ctx, "writing", "text:write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ToggleTodo(ctx, todos[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Trash(ctx, todos[3].ID); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := d.Queries.ListProjects(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	views, err := d.Queries.ListViews(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := d.countTabs(ctx, projects, views)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tabCounts{
+		active:    2,
+		completed: 1,
+		trashed:   1,
+		projects:  map[int]int{project.ID: 1},
+		views:     map[int]int{view.ID: 0},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("countTabs = %+v, want %+v", counts, want)
+	}
+
+	s := State{windowWidth: 200, projects: projects, views: views, tabCounts: counts}
+	tabs := s.renderTabs()
+	for _, text := range []string{"active: 2", "home: 1", "writing: 0", "complete: 1", "trash: 1"} {
+		if !strings.Contains(tabs, text) {
+			t.Errorf("tabs %q don't show %q", tabs, text)
+		}
+	}
+}
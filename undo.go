@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxUndo bounds how many actions the undo stack remembers.
+const maxUndo = 100
+
+// action is a change that has already been applied to the database, along
+// with how to reverse and reapply it. Ids stay stable across undo and redo
+// because deletes go through the trash rather than removing rows.
+type action struct {
+	label string
+	undo  func(ctx context.Context, db *Database) error
+	redo  func(ctx context.Context, db *Database) error
+}
+
+// replayedMsg reports an action that was undone (or redone, if redo is set).
+type replayedMsg struct {
+	action action
+	redo   bool
+}
+
+// pushAction returns stack with a on top, dropping the oldest entry past
+// maxUndo. The result never shares its backing array with stack, so earlier
+// States keep their own history.
+func pushAction(stack []action, a action) []action {
+	start := max(0, len(stack)+1-maxUndo)
+	next := make([]action, 0, len(stack)-start+1)
+	next = append(next, stack[start:]...)
+	return append(next, a)
+}
+
+// record adds a freshly applied action to the undo stack. Any new change
+// invalidates the redo stack.
+func (s State) record(a *action) State {
+	if a == nil {
+		return s
+	}
+	s.undoStack = pushAction(s.undoStack, *a)
+	s.redoStack = nil
+	return s
+}
+
+// undo reverses the most recent action.
+func (s State) undo() (State, tea.Cmd) {
+	if len(s.undoStack) == 0 {
+		s.status = "↺ nothing to undo"
+		return s, nil
+	}
+	a := s.undoStack[len(s.undoStack)-1]
+	s.undoStack = s.undoStack[: len(s.undoStack)-1 : len(s.undoStack)-1]
+	return s, s.replay(a, false)
+}
+
+// redo reapplies the most recently undone action.
+func (s State) redo() (State, tea.Cmd) {
+	if len(s.redoStack) == 0 {
+		s.status = "↺ nothing to redo"
+		return s, nil
+	}
+	a := s.redoStack[len(s.redoStack)-1]
+	s.redoStack = s.redoStack[: len(s.redoStack)-1 : len(s.redoStack)-1]
+	return s, s.replay(a, true)
+}
+
+func (s State) replay(a action, redo bool) tea.Cmd {
+	return tea.Cmd(func() tea.Msg {
+		ctx := context.Background()
+		apply := a.undo
+		if redo {
+			apply = a.redo
+		}
+		if err := apply(ctx, s.database); err != nil {
+			return tea.Msg(fmt.Sprintf("Error replaying %s: %v", a.label, err))
+		}
+		return replayedMsg{action: a, redo: redo}
+	})
+}
+
+func setContentAction(id int, prev, next string) *action {
+	set := func(content string) func(context.Context, *Database) error {
+		return func(ctx context.Context, db *Database) error {
+			return db.UpdateContent(ctx, id, content)
+		}
+	}
+	return &action{
+		label: fmt.Sprintf("edit %q", next),
+		undo:  set(prev),
+		redo:  set(next),
+	}
+}
+
+func setPriorityAction(id int, content string, prev, next Priority) *action {
+	set := func(p Priority) func(context.Context, *Database) error {
+		return func(ctx context.Context, db *Database) error {
+			return db.UpdatePriority(ctx, id, p)
+		}
+	}
+	return &action{
+		label: fmt.Sprintf("priority %s → %s on %q", prev, next, content),
+		undo:  set(prev),
+		redo:  set(next),
+	}
+}
+
+// trashAction covers both deleting a todo and creating one: undoing a
+// create moves the new todo to the trash, and undoing a delete restores it.
+func trashAction(label string, id int, trashed bool) *action {
+	trash := func(ctx context.Context, db *Database) error { return db.Trash(ctx, id) }
+	restore := func(ctx context.Context, db *Database) error { return db.Restore(ctx, id) }
+	if trashed {
+		return &action{label: label, undo: restore, redo: trash}
+	}
+	return &action{label: label, undo: trash, redo: restore}
+}
+
+// toggleAction flips a todo's completion. Undo puts back the workflow state
+// the todo had before, not just an open one, and restarts the timer that
+// completing it stopped.
+func toggleAction(todo orm.Todo, spawned *orm.Todo, timer *orm.TimeEntry) *action {
+	verb, status := "complete", StatusDone
+	if todo.Completed {
+		verb, status = "reopen", StatusTodo
+	}
+	a := statusAction(todo, status, spawned, timer)
+	a.label = fmt.Sprintf("%s %q", verb, todo.Content)
+	return a
+}
+
+// statusAction moves a todo between workflow states. Like toggleAction, a
+// next occurrence spawned by moving to done is trashed on undo and restored
+// on redo, and timer, the timer the move stopped if any, is restarted on
+// undo. Dependents follow on their own: reopening a todo blocks them again.
+func statusAction(todo orm.Todo, status Status, spawned *orm.Todo, timer *orm.TimeEntry) *action {
+	set := func(to Status, undo bool) func(context.Context, *Database) error {
+		return func(ctx context.Context, db *Database) error {
+			if _, err := db.setStatus(ctx, todo.ID, to, false); err != nil {
+				return err
+			}
+			return db.replayCompletion(ctx, spawned, timer, undo)
+		}
+	}
+	return &action{
+		label: fmt.Sprintf("%s → %s on %q", todo.Status, status, todo.Content),
+		undo:  set(Status(todo.Status), true),
+		redo:  set(status, false),
+	}
+}
+
+// completeSubtreeAction completes a todo along with its open subtasks. Undo
+// reopens the todo and each subtask it completed in the state it was in,
+// with their completion times, so the subtasks don't stay done when only the
+// parent is reopened.
+func completeSubtreeAction(todo orm.Todo, subtasks []orm.Todo, spawned *orm.Todo, timer *orm.TimeEntry) *action {
+	return &action{
+		label: fmt.Sprintf("complete %q and %d subtask(s)", todo.Content, len(subtasks)),
+		undo: func(ctx context.Context, db *Database) error {
+			if err := db.uncompleteWithSubtasks(ctx, todo, subtasks); err != nil {
+				return err
+			}
+			return db.replayCompletion(ctx, spawned, timer, true)
+		},
+		redo: func(ctx context.Context, db *Database) error {
+			if _, _, err := db.completeWithSubtasks(ctx, todo.ID, false); err != nil {
+				return err
+			}
+			return db.replayCompletion(ctx, spawned, timer, false)
+		},
+	}
+}
+
+// replayCompletion handles what a completion did besides completing: the
+// next occurrence it spawned goes to the trash on undo and comes back on
+// redo, and the timer it stopped runs again on undo. Redoing the completion
+// stops the timer again by itself.
+func (d *Database) replayCompletion(ctx context.Context, spawned *orm.Todo, timer *orm.TimeEntry, undo bool) error {
+	if spawned != nil {
+		restore := d.Restore
+		if undo {
+			restore = d.Trash
+		}
+		if err := restore(ctx, spawned.ID); err != nil {
+			return err
+		}
+	}
+	if timer != nil && undo {
+		return d.resumeTimer(ctx, timer.TodoID)
+	}
+	return nil
+}
+
+// stoppedTimer returns the running timer if completing the todos with the
+// given ids would stop it, or nil.
+func stoppedTimer(running *orm.TimeEntry, ids ...int) *orm.TimeEntry {
+	if running != nil && slices.Contains(ids, running.TodoID) {
+		return running
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+func TestCompleteSubtreeUndo(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	create := func(content string, parent int) orm.Todo {
+		t.Helper()
+		todo, err := d.Queries.CreateTodo(ctx, orm.CreateTodoParams{
+			Content:   content,
+			Priority:  string(P2),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			ParentID:  nullID(parent),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return todo
+	}
+	get := func(id int) orm.Todo {
+		t.Helper()
+		todo, err := d.Queries.GetTodo(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return todo
+	}
+	parent := create("release", 0)
+	doing := create("write notes", parent.ID)
+	done := create("tag build", parent.ID)
+	dependent := create("announce", 0)
+	if _, err := d.SetStatus(ctx, doing.ID, StatusDoing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ToggleTodo(ctx, done.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Link(ctx, dependent.ID, doing.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.StartTimer(ctx, doing.ID); err != nil {
+		t.Fatal(err)
+	}
+	parent, doing, done = get(parent.ID), get(doing.ID), get(done.ID)
+
+	running, err := d.RunningTimer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subtasks, next, err := d.CompleteWithSubtasks(ctx, parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtasks) != 1 || subtasks[0].ID != doing.ID {
+		t.Fatalf("CompleteWithSubtasks completed %v, want only #%d", todoIDs(subtasks), doing.ID)
+	}
+	if got := get(dependent.ID).Status; got != string(StatusTodo) {
+		t.Fatalf("dependent is %s after completing its blocker, want todo", got)
+	}
+	a := completeSubtreeAction(parent, subtasks, next, stoppedTimer(running, parent.ID, doing.ID))
+
+	if err := a.undo(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []orm.Todo{parent, doing, done} {
+		got := get(want.ID)
+		if got.Completed != want.Completed || got.Status != want.Status || got.CompletedAt.Valid != want.CompletedAt.Valid {
+			t.Errorf("#%d after undo: completed=%t status=%s completed_at=%v, want completed=%t status=%s completed_at=%v",
+				want.ID, got.Completed, got.Status, got.CompletedAt.Valid, want.Completed, want.Status, want.CompletedAt.Valid)
+		}
+	}
+	if got := get(dependent.ID).Status; got != string(StatusBlocked) {
+		t.Errorf("dependent is %s after undo, want blocked", got)
+	}
+	timer, err := d.RunningTimer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timer == nil || timer.TodoID != doing.ID {
+		t.Errorf("running timer after undo = %v, want one on #%d", timer, doing.ID)
+	}
+
+	if err := a.redo(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{parent.ID, doing.ID, done.ID} {
+		if !get(id).Completed {
+			t.Errorf("#%d is open after redo", id)
+		}
+	}
+	if got := get(dependent.ID).Status; got != string(StatusTodo) {
+		t.Errorf("dependent is %s after redo, want todo", got)
+	}
+	if timer, _ := d.RunningTimer(ctx); timer != nil {
+		t.Errorf("timer on #%d still runs after redo", timer.TodoID)
+	}
+}
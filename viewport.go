@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// todoPage records how far into its tab the list has been read, in top-level
+// todos since their subtasks come along with them, and the time its ages are
+// measured to, which later pages have to reuse.
+type todoPage struct {
+	last    int
+	fetched int
+	more    bool
+	at      time.Time
+}
+
+// newTodoPage describes a read of top-level todos that asked for at most
+// limit rows. Getting all of them means there may be more.
+func newTodoPage(todos []orm.Todo, limit int) todoPage {
+	page := todoPage{fetched: len(todos), more: limit > 0 && len(todos) == limit}
+	if len(todos) > 0 {
+		page.last = todos[len(todos)-1].ID
+	}
+	return page
+}
+
+type todoPageMsg struct {
+	tab     string
+	sort    Sort
+	after   int
+	todos   []orm.Todo
+	details todoDetails
+	page    todoPage
+	toEnd   bool
+}
+
+// todoDetails is what the list shows beside its todos, keyed by todo id. It
+// is read for the todos loaded, a page at a time, rather than for the whole
+// table.
+type todoDetails struct {
+	tags     map[int][]string
+	progress map[int]subtaskProgress
+	blockers map[int][]int
+	tracked  map[int]time.Duration
+}
+
+// loadDetails reads the details of the given todos.
+func (d *Database) loadDetails(ctx context.Context, todos []orm.Todo) (todoDetails, error) {
+	ids := todoIDs(todos)
+	tags, err := d.TodoTags(ctx, ids)
+	if err != nil {
+		return todoDetails{}, fmt.Errorf("loading tags: %w", err)
+	}
+	progress, err := d.SubtaskProgress(ctx, ids)
+	if err != nil {
+		return todoDetails{}, fmt.Errorf("loading subtasks: %w", err)
+	}
+	blockers, err := d.OpenBlockers(ctx, ids)
+	if err != nil {
+		return todoDetails{}, fmt.Errorf("loading dependencies: %w", err)
+	}
+	tracked, err := d.TrackedTime(ctx, ids)
+	if err != nil {
+		return todoDetails{}, fmt.Errorf("loading tracked time: %w", err)
+	}
+	return todoDetails{tags: tags, progress: progress, blockers: blockers, tracked: tracked}, nil
+}
+
+// pageLimit is how many todos a reload reads: at least a page, and as many
+// as were already read so the list doesn't shrink under the cursor. Search
+// and the board read the whole tab instead: search wraps from the last hit
+// to the first and jumps between tabs by their hits, and the board lays
+// every card out in its column, so both need all of the tab at hand. The
+// tab's filters still run in SQL, so that is the tab, not the whole table.
+func (s State) pageLimit() int {
+	if s.search != "" || s.showBoard {
+		return 0
+	}
+	return max(todoPageSize, s.page.fetched)
+}
+
+// loadMoreIfNeeded reads the next page once the cursor is within a screen of
+// the last todo loaded, or nil if there's nothing to read.
+func (s State) loadMoreIfNeeded() tea.Cmd {
+	if s.cursor < len(s.todos)-s.listHeight() {
+		return nil
+	}
+	return s.fetchPage(todoPageSize, false)
+}
+
+// loadRest reads every todo of the tab not loaded yet, for jumping to the
+// bottom, or nil if there's nothing to read.
+func (s State) loadRest() tea.Cmd {
+	return s.fetchPage(0, true)
+}
+
+// fetchPage reads up to limit todos (all if 0) after the last one loaded.
+// With toEnd the cursor follows to the last todo once they arrive.
+func (s State) fetchPage(limit int, toEnd bool) tea.Cmd {
+	if !s.page.more || s.fetching || s.viewMode == TrashView {
+		return nil
+	}
+	tab, sort, after, at := s.tabKey(), s.currentSort(), s.page.last, s.page.at
+	return tea.Cmd(func() tea.Msg {
+		filter, err := s.listFilter(s.views)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error %v", err))
+		}
+		todos, subtasks, err := s.database.ListTodoTrees(context.Background(), s.viewMode == CompletedView, filter, sort, at, after, limit)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error loading todos: %v", err))
+		}
+		page := newTodoPage(todos, limit)
+		page.fetched += s.page.fetched
+		page.at = at
+		if page.last == 0 {
+			page.last = after
+		}
+		todos = append(todos, subtasks...)
+		details, err := s.database.loadDetails(context.Background(), todos)
+		if err != nil {
+			return tea.Msg(fmt.Sprintf("Error %v", err))
+		}
+		return todoPageMsg{tab: tab, sort: sort, after: after, todos: todos, details: details, page: page, toEnd: toEnd}
+	})
+}
+
+// appendPage adds a page to the list, unless the tab, its sort, or a reload
+// has moved on since it was asked for.
+func (s State) appendPage(msg todoPageMsg) State {
+	s.fetching = false
+	if msg.tab != s.tabKey() || msg.sort != s.currentSort() || msg.after != s.page.last {
+		return s
+	}
+	s.loaded = append(slices.Clip(s.loaded), msg.todos...)
+	s.todos, s.tree = flattenTree(s.loaded, s.collapsed)
+	// The maps may be shared with earlier states, so they're copied before
+	// the page's details go in.
+	s.todoTags = maps.Clone(s.todoTags)
+	maps.Copy(s.todoTags, msg.details.tags)
+	s.progress = maps.Clone(s.progress)
+	maps.Copy(s.progress, msg.details.progress)
+	s.blockers = maps.Clone(s.blockers)
+	maps.Copy(s.blockers, msg.details.blockers)
+	s.tracked = maps.Clone(s.tracked)
+	maps.Copy(s.tracked, msg.details.tracked)
+	s.page = msg.page
+	if msg.toEnd {
+		s.cursor = max(0, len(s.todos)-1)
+	}
+	return s
+}
+
+// listHeight is how many lines of todos fit in the space under the title,
+// after the tabs above them and the scroll indicator below.
+func (s State) listHeight() int {
+	return max(1, s.windowHeight-len(asciiArt)-4-6)
+}
+
+// scrollToCursor moves the top of the list just far enough to show the
+// cursor, without leaving blank lines below the last todo.
+func (s State) scrollToCursor() State {
+	h := s.listHeight()
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+h {
+		s.offset = s.cursor - h + 1
+	}
+	s.offset = max(0, min(s.offset, len(s.todos)-h))
+	return s
+}
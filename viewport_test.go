@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+// TestListFilterPages checks that paging through a tab with its filters in
+// the SQL finds the same todos, in the same order, as filtering the whole
+// list afterwards.
+func TestListFilterPages(t *testing.T) {
+	d := newTestDatabase(t)
+	seedSortTodos(t, d)
+	ctx := context.Background()
+	now := time.Now()
+	project, err := d.CreateProject(ctx, "home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := d.CreateView(ctx, "urgent", "priority:P0,P1 -text:c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	views, err := d.Queries.ListViews(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := d.ListTodos(ctx, false, Filter{}, Sort{Mode: SortCreated}, now, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, todo := range all {
+		if i%3 == 1 {
+			err := d.Queries.MoveTodoToProject(ctx, orm.MoveTodoToProjectParams{ProjectID: nullID(project.ID), UpdatedAt: now, ID: todo.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i%2 == 0 {
+			if err := d.SetTags(ctx, todo.ID, []string{"work"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i%5 == 0 && i+1 < len(all) {
+			if err := d.Link(ctx, todo.ID, all[i+1].ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	done, err := d.ListTodos(ctx, true, Filter{}, Sort{Mode: SortCreated}, now, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, todo := range done {
+		if i%2 == 0 {
+			if err := d.SetTags(ctx, todo.ID, []string{"work"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	query, err := ParseFilter("created:<1000d", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := todoIDs(slices.Concat(all, done))
+	tags, err := d.TodoTags(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockers, err := d.OpenBlockers(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		state State
+		want  func([]orm.Todo) []orm.Todo
+	}{
+		{
+			"project",
+			State{viewMode: ProjectView, projectID: project.ID},
+			func(todos []orm.Todo) []orm.Todo {
+				var kept []orm.Todo
+				for _, todo := range todos {
+					if todo.ProjectID.Int64 == int64(project.ID) {
+						kept = append(kept, todo)
+					}
+				}
+				return kept
+			},
+		},
+		{
+			"tag and unblocked",
+			State{viewMode: ActiveView, tagFilter: []string{"work"}, hideBlocked: true},
+			func(todos []orm.Todo) []orm.Todo {
+				var kept []orm.Todo
+				for _, todo := range filterByTags(todos, tags, []string{"work"}) {
+					if len(blockers[todo.ID]) == 0 {
+						kept = append(kept, todo)
+					}
+				}
+				return kept
+			},
+		},
+		{
+			"view and status",
+			State{viewMode: SavedView, viewID: view.ID, statusFilter: StatusBlocked},
+			func(todos []orm.Todo) []orm.Todo {
+				var kept []orm.Todo
+				for _, todo := range todos {
+					if Status(todo.Status) == StatusBlocked && (todo.Priority == "P0" || todo.Priority == "P1") && todo.Content[0] != 'c' {
+						kept = append(kept, todo)
+					}
+				}
+				return kept
+			},
+		},
+		{
+			"completed with query",
+			// Status and blocked filters only apply to open todos.
+			State{viewMode: CompletedView, filter: query, tagFilter: []string{"work"}, hideBlocked: true},
+			func(todos []orm.Todo) []orm.Todo { return filterByTags(todos, tags, []string{"work"}) },
+		},
+	}
+	for _, tt := range tests {
+		completed := tt.state.viewMode == CompletedView
+		sort := Sort{Mode: SortDue, Desc: true}
+		everything, err := d.ListTodos(ctx, completed, Filter{}, sort, now, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := todoIDs(tt.want(everything))
+		if len(want) == 0 {
+			t.Fatalf("%s: no todos to list", tt.name)
+		}
+		filter, err := tt.state.listFilter(views)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []int
+		after := 0
+		for range len(everything) + 1 {
+			page, err := d.ListTodos(ctx, completed, filter, sort, now, after, 3)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			got = append(got, todoIDs(page)...)
+			if len(page) < 3 {
+				break
+			}
+			after = page[len(page)-1].ID
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: pages give\n\t%v\nwant\n\t%v", tt.name, got, want)
+		}
+	}
+}
+
+// TestListTodoTreesPages checks that paging by top-level todos brings each
+// subtask in with its parent, however far apart the sort puts them, and
+// that the pages add up to the whole tab.
+func TestListTodoTreesPages(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	now := time.Now()
+	base := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+	create := func(content string, parent int, created time.Duration) orm.Todo {
+		t.Helper()
+		todo, err := d.Queries.CreateTodo(ctx, orm.CreateTodoParams{
+			Content:   content,
+			Priority:  string(P2),
+			CreatedAt: base.Add(created),
+			UpdatedAt: base,
+			ParentID:  nullID(parent),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return todo
+	}
+	var roots []orm.Todo
+	for i := range 6 {
+		roots = append(roots, create(fmt.Sprintf("root %d", i), 0, time.Duration(i+1)*time.Hour))
+	}
+	// Subtasks that sort well before or after their parents.
+	late := create("late subtask", roots[0].ID, 100*time.Hour)
+	create("early grandchild", late.ID, -time.Hour)
+	create("early subtask", roots[4].ID, -2*time.Hour)
+	// A subtask whose parent is in another tab is at the top of this one.
+	create("orphan", roots[5].ID, 0)
+	err := d.Queries.ToggleTodoCompleted(ctx, orm.ToggleTodoCompletedParams{CompletedAt: sql.NullTime{Time: base, Valid: true}, UpdatedAt: base, ID: roots[5].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort := Sort{Mode: SortCreated}
+	all, err := d.ListTodos(ctx, false, Filter{}, sort, now, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := flattenTree(all, nil)
+	var loaded []orm.Todo
+	pages, tops, after := 0, 0, 0
+	for range len(all) + 1 {
+		top, subtasks, err := d.ListTodoTrees(ctx, false, Filter{}, sort, now, after, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := append(top, subtasks...)
+		present := map[int]bool{}
+		for _, todo := range page {
+			present[todo.ID] = true
+		}
+		for _, todo := range subtasks {
+			if !present[int(todo.ParentID.Int64)] {
+				t.Errorf("page %d has subtask %q without its parent", pages, todo.Content)
+			}
+		}
+		loaded = append(loaded, page...)
+		pages++
+		tops += len(top)
+		if len(top) < 2 {
+			break
+		}
+		after = top[len(top)-1].ID
+	}
+	got, _ := flattenTree(loaded, nil)
+	if !slices.Equal(todoIDs(got), todoIDs(want)) {
+		t.Errorf("pages give\n\t%v\nwant\n\t%v", todoIDs(got), todoIDs(want))
+	}
+	if tops != 6 {
+		t.Errorf("read %d top-level todos, want 6", tops)
+	}
+}
+
+// TestLoadDetails checks that a page's details are read for its todos alone,
+// and that appending a page adds its details to those already loaded.
+func TestLoadDetails(t *testing.T) {
+	d := newTestDatabase(t)
+	ctx := context.Background()
+	todos := createTestTodos(t, d, "a", "b", "c")
+	a, b, c := todos[0], todos[1], todos[2]
+	for _, todo := range []orm.Todo{a, c} {
+		if err := d.SetTags(ctx, todo.ID, []string{"work"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Link(ctx, c.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	first, err := d.loadDetails(ctx, []orm.Todo{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.tags) != 1 || len(first.tags[a.ID]) != 1 || len(first.blockers) != 0 {
+		t.Fatalf("details of a and b = %+v, want a's tag alone", first)
+	}
+	second, err := d.loadDetails(ctx, []orm.Todo{c})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := State{loaded: []orm.Todo{a, b}, todoTags: first.tags, progress: first.progress, blockers: first.blockers, tracked: first.tracked}
+	s = s.appendPage(todoPageMsg{tab: s.tabKey(), sort: s.currentSort(), todos: []orm.Todo{c}, details: second})
+	if len(s.todos) != 3 || len(s.todoTags) != 2 || !slices.Equal(s.blockers[c.ID], []int{b.ID}) {
+		t.Errorf("after the second page, tags = %v and blockers = %v; want both pages'", s.todoTags, s.blockers)
+	}
+	if len(first.tags) != 1 {
+		t.Errorf("appending a page changed the first page's tags to %v", first.tags)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewjmcgehee/godoit/internal/orm"
+)
+
+const maxViewNameLength = 32
+
+// NormalizeViewName trims a saved view's name and checks it fits in a tab.
+func NormalizeViewName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errors.New("view name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > maxViewNameLength {
+		return "", fmt.Errorf("view name must be at most %d characters", maxViewNameLength)
+	}
+	return name, nil
+}
+
+// LookupView finds a saved view by name, turning a missing row into a
+// readable error.
+func (d *Database) LookupView(ctx context.Context, name string) (orm.SavedView, error) {
+	view, err := d.Queries.GetViewByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return view, fmt.Errorf("no view named %q", name)
+	}
+	return view, err
+}
+
+// CreateView saves a filter query under a name, rejecting names that are
+// already taken and queries that don't parse or filter nothing.
+func (d *Database) CreateView(ctx context.Context, name, query string) (orm.SavedView, error) {
+	filter, err := ParseFilter(query, time.Now())
+	if err != nil {
+		return orm.SavedView{}, err
+	}
+	if !filter.Active() {
+		return orm.SavedView{}, errors.New("view query must not be empty")
+	}
+	if _, err := d.Queries.GetViewByName(ctx, name); err == nil {
+		return orm.SavedView{}, fmt.Errorf("view %q already exists", name)
+	}
+	return d.Queries.CreateView(ctx, orm.CreateViewParams{
+		Name:      name,
+		Query:     filter.Source,
+		CreatedAt: time.Now(),
+	})
+}
+
+// viewFilter parses a saved view's query. Relative times are measured from
+// now each time, so "created:<7d" keeps meaning the last week.
+func viewFilter(views []orm.SavedView, id int, now time.Time) (Filter, error) {
+	for _, v := range views {
+		if v.ID == id {
+			filter, err := ParseFilter(v.Query, now)
+			if err != nil {
+				return Filter{}, fmt.Errorf("view %q: %w", v.Name, err)
+			}
+			return filter, nil
+		}
+	}
+	return Filter{}, fmt.Errorf("no view with id %d", id)
+}
+
+// CountView counts the open todos matching a view's filter.
+func (d *Database) CountView(ctx context.Context, f Filter) (int, error) {
+	query, args := f.SQL()
+	var count int
+	err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM todos WHERE completed = FALSE AND id IN ("+query+")", args...).Scan(&count)
+	return count, err
+}
+
+// viewName returns the name of the saved view with the given id, or "".
+func viewName(views []orm.SavedView, id int) string {
+	for _, v := range views {
+		if v.ID == id {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// viewQuery returns the query of the saved view with the given id, or "".
+func viewQuery(views []orm.SavedView, id int) string {
+	for _, v := range views {
+		if v.ID == id {
+			return v.Query
+		}
+	}
+	return ""
+}